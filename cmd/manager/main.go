@@ -8,33 +8,39 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
 
 	"openshift/aws-efs-operator/pkg/apis"
-	"openshift/aws-efs-operator/pkg/controller"
+	"openshift/aws-efs-operator/pkg/controller/sharedvolume"
 	"openshift/aws-efs-operator/pkg/controller/statics"
+	"openshift/aws-efs-operator/pkg/health"
+	"openshift/aws-efs-operator/pkg/util"
+	svwebhook "openshift/aws-efs-operator/pkg/webhook/sharedvolume"
 	"openshift/aws-efs-operator/version"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	kubemetrics "github.com/operator-framework/operator-sdk/pkg/kube-metrics"
-	"github.com/operator-framework/operator-sdk/pkg/leader"
 	"github.com/operator-framework/operator-sdk/pkg/log/zap"
 	"github.com/operator-framework/operator-sdk/pkg/metrics"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 	"github.com/spf13/pflag"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
-	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
+	configv1 "github.com/openshift/api/config/v1"
 	securityv1 "github.com/openshift/api/security/v1"
 )
 
@@ -44,6 +50,27 @@ var (
 	metricsPort         int32 = 8383
 	operatorMetricsPort int32 = 8686
 )
+
+// The webhook server listens on this port. It must match the `containerPort` in the
+// ClusterServiceVersion's `webhookdefinitions`.
+var webhookPort = 9443
+
+// The liveness (/healthz) and readiness (/readyz) probes are served on this address. It must match
+// the probes in deploy/operator.yaml.
+var healthProbeAddr = ":8081"
+
+// The name of the Lease through which replicas elect a leader.
+const leaderElectionID = "aws-efs-operator-lock"
+
+// Leader election settings. With the defaults, a standby replica takes over within about
+// leaseDuration of the leader's node dying, and at once when the leader shuts down cleanly.
+var (
+	leaderElect   = true
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -62,6 +89,20 @@ func main() {
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
+	pflag.IntVar(&sharedvolume.MaxConcurrentReconciles, "max-concurrent-reconciles", sharedvolume.MaxConcurrentReconciles,
+		"The number of SharedVolumes to reconcile in parallel.")
+	pflag.DurationVar(&util.ReconcileTimeout, "reconcile-timeout", util.ReconcileTimeout,
+		"How long a single reconcile may take before it is abandoned.")
+
+	pflag.BoolVar(&leaderElect, "leader-elect", leaderElect,
+		"Elect a leader among the operator's replicas, so only one of them runs the controllers at a time.")
+	pflag.DurationVar(&leaseDuration, "leader-election-lease-duration", leaseDuration,
+		"How long standby replicas wait before taking over a Lease the leader has stopped renewing.")
+	pflag.DurationVar(&renewDeadline, "leader-election-renew-deadline", renewDeadline,
+		"How long the leader keeps trying to renew its Lease before giving up leadership (and exiting).")
+	pflag.DurationVar(&retryPeriod, "leader-election-retry-period", retryPeriod,
+		"How often replicas try to acquire or renew the Lease.")
+
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -76,6 +117,11 @@ func main() {
 
 	printVersion()
 
+	if err := validateLeaderElection(); err != nil {
+		log.Error(err, "Invalid leader election settings")
+		os.Exit(1)
+	}
+
 	namespace, err := k8sutil.GetWatchNamespace()
 	if err != nil {
 		log.Error(err, "Failed to get watch namespace")
@@ -95,18 +141,34 @@ func main() {
 		os.Exit(1)
 	}
 
-	ctx := context.TODO()
-	// Become the leader before proceeding
-	err = leader.Become(ctx, "aws-efs-operator-lock")
-	if err != nil {
-		log.Error(err, "")
-		os.Exit(1)
-	}
-
 	// Set default manager options
-	options := manager.Options{
+	options := ctrl.Options{
 		Namespace:          namespace,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		// The serving certificate is expected in the default location, /tmp/k8s-webhook-server/serving-certs
+		Port:                   webhookPort,
+		HealthProbeBindAddress: healthProbeAddr,
+		// Only one replica runs the controllers at a time. The others wait on the Lease.
+		LeaderElection:             leaderElect,
+		LeaderElectionResourceLock: "leases",
+		LeaderElectionID:           leaderElectionID,
+		LeaseDuration:              &leaseDuration,
+		RenewDeadline:              &renewDeadline,
+		RetryPeriod:                &retryPeriod,
+		// Hand over the Lease when we stop, rather than making the next leader wait for it to
+		// expire. This is safe because we exit as soon as the manager has stopped.
+		LeaderElectionReleaseOnCancel: true,
+	}
+
+	// Leader election needs a namespace for its Lease, which we only know in a cluster. Run locally,
+	// there's only the one of us anyway.
+	if _, err := k8sutil.GetOperatorNamespace(); err != nil && leaderElect {
+		if !errors.Is(err, k8sutil.ErrRunLocal) {
+			log.Error(err, "Failed to get operator namespace")
+			os.Exit(1)
+		}
+		log.Info("Skipping leader election; not running in a cluster.")
+		options.LeaderElection = false
 	}
 
 	// Add support for MultiNamespace set in WATCH_NAMESPACE (e.g ns1,ns2)
@@ -124,7 +186,7 @@ func main() {
 	}
 
 	// Create a new manager to provide shared dependencies and start components
-	mgr, err := manager.New(cfg, options)
+	mgr, err := ctrl.NewManager(cfg, options)
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
@@ -144,6 +206,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Need this for the Infrastructure Kind, from which we discover the AWS region
+	if err := configv1.Install(mgr.GetScheme()); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Need this for the CustomResourceDefinition Kind
 	if err := apiextensions.AddToScheme(mgr.GetScheme()); err != nil {
 		log.Error(err, "")
@@ -151,36 +219,83 @@ func main() {
 	}
 
 	// Setup all Controllers
-	if err := controller.AddToManager(mgr); err != nil {
+	if err := sharedvolume.Add(mgr); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
-
-	// Create k8s client to perform startup tasks.
-	startupClient, err := crclient.New(cfg, crclient.Options{Scheme: mgr.GetScheme()})
-	if err != nil {
-		log.Error(err, "Unable to create operator startup client")
+	if err := statics.Add(mgr); err != nil {
+		log.Error(err, "")
 		os.Exit(1)
 	}
 
-	// Ensure static resources are created.
-	if err := statics.EnsureStatics(log, startupClient); err != nil {
-		log.Error(err, "Couldn't bootstrap static resources")
+	// Setup all Webhooks, unless disabled. Disabling is useful when running the operator locally,
+	// where the webhook server wouldn't have a serving certificate and couldn't be reached by the
+	// API server anyway.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := svwebhook.Add(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	} else {
+		log.Info("Webhooks are disabled.")
+	}
+
+	// Liveness only says the process is up and serving. Readiness says it's fully started: the
+	// SharedVolume CRD is there, the caches are synced, and (if we're the leader) the static
+	// resources have been ensured.
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		log.Error(err, "Unable to set up health check")
 		os.Exit(1)
 	}
+	readyChecks := map[string]healthz.Checker{
+		"statics": health.LeaderOnly(mgr.Elected(), statics.Ensured.Check),
+		// Use the API reader so we don't set up a watch on CRDs just for this.
+		"crd":    statics.CRDDiscovered(mgr.GetAPIReader()),
+		"caches": health.CachesSynced(mgr.GetCache()),
+	}
+	for name, check := range readyChecks {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			log.Error(err, "Unable to set up ready check", "check", name)
+			os.Exit(1)
+		}
+	}
 
-	// Add the Metrics Service
-	addMetrics(ctx, cfg)
+	// The statics controller creates the static resources as soon as it starts, and keeps trying
+	// if some of them fail, reporting them in the OperatorConfig's status meanwhile. Adding the
+	// metrics Service is likewise only for the leader.
+	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		addMetrics(ctx, cfg)
+		return nil
+	}))
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
 
 	log.Info("Starting the Cmd.")
 
-	// Start the Cmd
+	// Start the Cmd. It stops on SIGTERM/SIGINT, canceling everything in flight.
 	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
 		log.Error(err, "Manager exited non-zero")
 		os.Exit(1)
 	}
 }
 
+// validateLeaderElection checks the leader election flags against each other, as client-go would
+// when the manager starts, but with a clearer message.
+func validateLeaderElection() error {
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("--leader-election-lease-duration (%v) must be greater than --leader-election-renew-deadline (%v)",
+			leaseDuration, renewDeadline)
+	}
+	// The retries are jittered
+	if renewDeadline <= time.Duration(leaderelection.JitterFactor*float64(retryPeriod)) {
+		return fmt.Errorf("--leader-election-renew-deadline (%v) must be greater than %v times --leader-election-retry-period (%v)",
+			renewDeadline, leaderelection.JitterFactor, retryPeriod)
+	}
+	return nil
+}
+
 // addMetrics will create the Services and Service Monitors to allow the operator export the metrics by using
 // the Prometheus operator
 func addMetrics(ctx context.Context, cfg *rest.Config) {
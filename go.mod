@@ -3,19 +3,22 @@ module openshift/aws-efs-operator
 go 1.16
 
 require (
+	github.com/aws/aws-sdk-go v1.38.70
 	github.com/go-logr/logr v0.4.0
 	github.com/go-logr/zapr v0.4.0 // indirect
 	github.com/golang/mock v1.4.3
 	github.com/google/go-cmp v0.5.2
 	github.com/openshift/api v0.0.0-20210928121311-b64fe3d0dc32
 	github.com/operator-framework/operator-sdk v0.18.2
+	github.com/prometheus/client_golang v1.7.1
+	github.com/prometheus/client_model v0.2.0
 	github.com/spf13/pflag v1.0.5
 	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
 	k8s.io/api v0.19.14
 	k8s.io/apiextensions-apiserver v0.19.7
 	k8s.io/apimachinery v0.19.14
 	k8s.io/client-go v12.0.0+incompatible
-	sigs.k8s.io/controller-runtime v0.6.5
+	sigs.k8s.io/controller-runtime v0.7.0
 	sigs.k8s.io/yaml v1.2.0
 )
 
@@ -0,0 +1,182 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorConfigName is the name of the singleton OperatorConfig. OperatorConfigs with any other
+// name are ignored.
+const OperatorConfigName = "cluster"
+
+// OperatorConfigSpec defines overrides for how the operator deploys the EFS CSI driver. Anything
+// left unset gets the operator's built-in default.
+type OperatorConfigSpec struct {
+	// DriverImage is the image of the EFS CSI driver, e.g. to pull it from a mirror registry.
+	// +optional
+	DriverImage string `json:"driverImage,omitempty"`
+	// RegistrarImage is the image of the CSI node driver registrar sidecar.
+	// +optional
+	RegistrarImage string `json:"registrarImage,omitempty"`
+	// LivenessProbeImage is the image of the CSI liveness probe sidecar.
+	// +optional
+	LivenessProbeImage string `json:"livenessProbeImage,omitempty"`
+	// ImagePullPolicy applies to all of the above. The default is `Always`.
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// LogLevel is the log verbosity (`--v`) of the driver and registrar. The default is 5.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LogLevel *int32 `json:"logLevel,omitempty"`
+	// ImagePullSecrets lists Secrets, in the operator's namespace, used to pull the images.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// NodeSelector replaces the default node selector of the driver's pods, which selects Linux
+	// worker nodes. Use Architectures, not this, to choose CPU architectures.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Architectures lists the CPU architectures of the nodes on which to run the driver. The
+	// default is amd64 only. The images must support all of them.
+	// +optional
+	Architectures []Architecture `json:"architectures,omitempty"`
+	// Tolerations replaces the default tolerations of the driver's pods, which tolerate every
+	// taint.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// PriorityClassName is the priority class of the driver's pods, e.g.
+	// `system-node-critical`. By default they get none.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// DynamicProvisioning, if set, makes the operator deploy the CSI driver's controller, and a
+	// StorageClass through which PersistentVolumeClaims get access points created automatically.
+	// +optional
+	DynamicProvisioning *DynamicProvisioning `json:"dynamicProvisioning,omitempty"`
+	// StorageClasses lists additional StorageClasses of the EFS CSI driver for the operator to
+	// manage, e.g. to keep scratch and durable data apart. Those with a FileSystemID provision
+	// access points dynamically, like the one from DynamicProvisioning; SharedVolumes can use any
+	// of them via their StorageClassName.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	StorageClasses []StorageClassConfig `json:"storageClasses,omitempty"`
+}
+
+// DynamicProvisioning describes the StorageClass through which access points are provisioned.
+type DynamicProvisioning struct {
+	// The ID of the EFS volume in which to create access points, e.g. `fs-0123cdef`. Required.
+	// +kubebuilder:validation:Pattern=^fs-[0-9a-f]+$
+	FileSystemID string `json:"fileSystemID"`
+	// DirectoryPerms are the octal permissions of the root directory created for each access
+	// point. The default is `700`.
+	// +kubebuilder:validation:Pattern=`^[0-7]{3,4}$`
+	// +optional
+	DirectoryPerms string `json:"directoryPerms,omitempty"`
+	// BasePath is the path in the file system under which the access points' root directories are
+	// created. The default is the root of the file system.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+	// ControllerImage is the image of the EFS CSI driver run as the controller. It must support
+	// dynamic provisioning.
+	// +optional
+	ControllerImage string `json:"controllerImage,omitempty"`
+	// ProvisionerImage is the image of the CSI external provisioner sidecar.
+	// +optional
+	ProvisionerImage string `json:"provisionerImage,omitempty"`
+}
+
+// StorageClassConfig describes a StorageClass managed by the operator.
+type StorageClassConfig struct {
+	// Name is the name of the StorageClass. It may not be that of one of the operator's built-in
+	// StorageClasses, `efs-sc` and `efs-ap-sc`.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Name string `json:"name"`
+	// ReclaimPolicy is what happens to dynamically provisioned volumes, and their access points,
+	// when their claims are deleted. The default is `Delete`.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	ReclaimPolicy *corev1.PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+	// MountOptions are used to mount dynamically provisioned volumes, e.g. `tls`.
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty"`
+	// FileSystemID, if set, makes the StorageClass provision an access point on this EFS volume
+	// for each claim. The operator then deploys the CSI driver's controller, as for
+	// DynamicProvisioning.
+	// +kubebuilder:validation:Pattern=^fs-[0-9a-f]+$
+	// +optional
+	FileSystemID string `json:"fileSystemID,omitempty"`
+	// DirectoryPerms are the octal permissions of the root directory created for each access
+	// point. The default is `700`. Only used with FileSystemID.
+	// +kubebuilder:validation:Pattern=`^[0-7]{3,4}$`
+	// +optional
+	DirectoryPerms string `json:"directoryPerms,omitempty"`
+	// BasePath is the path in the file system under which the access points' root directories are
+	// created. The default is the root of the file system. Only used with FileSystemID.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+}
+
+// Architecture is a CPU architecture, as found in the `kubernetes.io/arch` node label.
+// +kubebuilder:validation:Enum=amd64;arm64
+type Architecture string
+
+// Architectures supported by the driver
+const (
+	ArchitectureAMD64 Architecture = "amd64"
+	ArchitectureARM64 Architecture = "arm64"
+)
+
+// Condition types reported in `OperatorConfigStatus.Conditions`
+const (
+	// OperatorConfigDegraded is True when the operator couldn't create, update or delete some of
+	// the static resources (the CSI driver and friends). It keeps trying; `FailedStatics` says
+	// which.
+	OperatorConfigDegraded = "Degraded"
+)
+
+// OperatorConfigStatus reports how the operator is doing at deploying the EFS CSI driver.
+type OperatorConfigStatus struct {
+	// Conditions describe the state of the static resources. See the OperatorConfig* condition
+	// type consts for possible values.
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// FailedStatics lists the static resources, as `Kind/name`, that the operator most recently
+	// failed to ensure.
+	// +optional
+	FailedStatics []string `json:"failedStatics,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OperatorConfig is the Schema for the operatorconfigs API. It lets cluster administrators
+// customize the EFS CSI driver deployed by the operator. Only one, named `cluster`, is honored.
+// The operator reports on the static resources in its status, if it exists. Without one, a failure
+// is only reported by an Event on the OperatorConfig CRD and the statics_degraded metric.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=operatorconfigs,scope=Cluster
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`
+type OperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OperatorConfigSpec   `json:"spec,omitempty"`
+	Status OperatorConfigStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OperatorConfigList contains a list of OperatorConfig
+type OperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorConfig{}, &OperatorConfigList{})
+}
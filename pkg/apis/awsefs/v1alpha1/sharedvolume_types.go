@@ -13,16 +13,132 @@ type SharedVolumeSpec struct {
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
 
-	// The ID of the EFS volume, e.g. `fs-0123cdef`. Required. Immutable.
+	// The ID of the EFS volume, e.g. `fs-0123cdef`. Required unless Source is specified.
+	// Immutable.
 	// +kubebuilder:validation:Pattern=^fs-[0-9a-f]+$
-	FileSystemID string `json:"fileSystemID"`
+	// +optional
+	FileSystemID string `json:"fileSystemID,omitempty"`
 	// The ID of an EFS volume access point, e.g. `fsap-0123456789abcdef`.
 	// The EFS volume will be mounted to the specified access point.
-	// Required. Immutable.
+	// Exactly one of AccessPointID, AccessPoint or Source must be specified. Immutable.
 	// +kubebuilder:validation:Pattern=^fsap-[0-9a-f]+$
-	AccessPointID string `json:"accessPointID"`
+	// +optional
+	AccessPointID string `json:"accessPointID,omitempty"`
+	// AccessPoint describes an access point the operator should create in the EFS volume on behalf
+	// of this SharedVolume. The ID of the created access point is reported in the Status.
+	// Exactly one of AccessPointID, AccessPoint or Source must be specified. Immutable.
+	// +optional
+	AccessPoint *AccessPointSpec `json:"accessPoint,omitempty"`
+	// Source is the name of a SharedVolumeSource, published by the cluster administrators, from
+	// which to take the file system and access point. The SharedVolume's namespace must be one of
+	// the source's AllowedNamespaces. The IDs used are reported in the Status.
+	// Exactly one of AccessPointID, AccessPoint or Source must be specified. Immutable.
+	// +optional
+	Source string `json:"source,omitempty"`
+	// ReadOnly, if true, makes the generated PersistentVolume and PersistentVolumeClaim
+	// ReadOnlyMany, and has the CSI driver mount the file system read-only, so pods using the
+	// claim can't write to it regardless of how they mount it. Defaults to false. Immutable.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// EncryptInTransit, if true, has the CSI driver mount the file system with TLS, so that NFS
+	// traffic is encrypted. Defaults to false. Immutable.
+	// +optional
+	EncryptInTransit bool `json:"encryptInTransit,omitempty"`
+	// IAMAuthorization, if true, has the CSI driver mount the file system using the IAM identity
+	// of the node, so that access can be controlled by the file system policy. Requires
+	// EncryptInTransit. Defaults to false. Immutable.
+	// +optional
+	IAMAuthorization bool `json:"iamAuthorization,omitempty"`
+	// ClaimName is the name of the PersistentVolumeClaim the operator creates in the
+	// SharedVolume's namespace. Defaults to `pvc-<name>`, where `<name>` is the name of the
+	// SharedVolume. It must not be the name of a PersistentVolumeClaim that already exists.
+	// Immutable.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+	// StorageClassName is the StorageClass of the generated PersistentVolume and
+	// PersistentVolumeClaim. It must be a StorageClass of the EFS CSI driver, e.g. one listed in
+	// the OperatorConfig, so that claims can be told apart (e.g. by quota) by class. Defaults to
+	// `efs-sc`. Immutable.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// ClaimLabels are added to the labels of the generated PersistentVolumeClaim and
+	// PersistentVolume, e.g. so they can be selected by backup tooling. Immutable.
+	// +optional
+	ClaimLabels map[string]string `json:"claimLabels,omitempty"`
+	// ClaimAnnotations are added to the annotations of the generated PersistentVolumeClaim and
+	// PersistentVolume. Immutable.
+	// +optional
+	ClaimAnnotations map[string]string `json:"claimAnnotations,omitempty"`
 }
 
+// AccessPointSpec describes an EFS access point to be provisioned by the operator.
+type AccessPointSpec struct {
+	// PosixUser is the POSIX identity with which all file system operations through the access
+	// point are performed, regardless of the identity of the pod.
+	PosixUser PosixUser `json:"posixUser"`
+	// RootDirectory is the directory in the EFS volume exposed as the root of the access point.
+	// If omitted, the root of the EFS volume is used.
+	// +optional
+	RootDirectory *RootDirectory `json:"rootDirectory,omitempty"`
+	// ReclaimPolicy indicates what should happen to the access point when the SharedVolume is
+	// deleted. Defaults to Retain. Note that deleting an access point does not delete any data in
+	// the EFS volume.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	ReclaimPolicy AccessPointReclaimPolicy `json:"reclaimPolicy,omitempty"`
+}
+
+// PosixUser is the POSIX identity used by an access point.
+type PosixUser struct {
+	// UID is the POSIX user ID.
+	// +kubebuilder:validation:Minimum=0
+	UID int64 `json:"uid"`
+	// GID is the POSIX group ID.
+	// +kubebuilder:validation:Minimum=0
+	GID int64 `json:"gid"`
+	// SecondaryGIDs are additional POSIX group IDs.
+	// +optional
+	SecondaryGIDs []int64 `json:"secondaryGIDs,omitempty"`
+}
+
+// RootDirectory describes the directory exposed as the root of an access point.
+type RootDirectory struct {
+	// Path is the absolute path of the directory within the EFS volume, e.g. `/data/proj1`.
+	// +kubebuilder:validation:Pattern=^/
+	Path string `json:"path"`
+	// CreationInfo, if specified, causes EFS to create Path with the given ownership and
+	// permissions if it does not already exist. If omitted and Path does not exist, mounting
+	// the access point will fail.
+	// +optional
+	CreationInfo *CreationInfo `json:"creationInfo,omitempty"`
+}
+
+// CreationInfo describes the ownership and permissions of an access point's root directory.
+type CreationInfo struct {
+	// OwnerUID is the POSIX user ID to own the directory.
+	// +kubebuilder:validation:Minimum=0
+	OwnerUID int64 `json:"ownerUID"`
+	// OwnerGID is the POSIX group ID to own the directory.
+	// +kubebuilder:validation:Minimum=0
+	OwnerGID int64 `json:"ownerGID"`
+	// Permissions are the POSIX permissions for the directory, in octal, e.g. `0755`.
+	// +kubebuilder:validation:Pattern=`^[0-7]{3,4}$`
+	Permissions string `json:"permissions"`
+}
+
+// AccessPointReclaimPolicy are possible values for `AccessPointSpec.ReclaimPolicy`
+type AccessPointReclaimPolicy string
+
+const (
+	// AccessPointRetain means the provisioned access point is left alone when the SharedVolume is
+	// deleted.
+	AccessPointRetain AccessPointReclaimPolicy = "Retain"
+	// AccessPointDelete means the provisioned access point is deleted along with the SharedVolume.
+	AccessPointDelete AccessPointReclaimPolicy = "Delete"
+)
+
 // SharedVolumePhase are possible values for `SharedVolumeStatus.Phase`
 type SharedVolumePhase string
 
@@ -30,10 +146,19 @@ const (
 	// SharedVolumePending indicates that we've noticed the SharedVolume and are working on
 	// creating its associated resources.
 	SharedVolumePending SharedVolumePhase = "Pending"
-	// SharedVolumeReady means we've created the resources associated with the SharedVolume,
-	// successfully as far as we can tell. Importantly, this does not imply that the
-	// PersistentVolumeClaim is bound, etc. It's up to the consumer to check that.
+	// SharedVolumeBinding means we've created the resources associated with the SharedVolume, but
+	// the PersistentVolumeClaim isn't bound to the PersistentVolume yet.
+	SharedVolumeBinding SharedVolumePhase = "Binding"
+	// SharedVolumeReady means we've created the resources associated with the SharedVolume, and
+	// the PersistentVolumeClaim is bound to the PersistentVolume, so pods can use it.
 	SharedVolumeReady SharedVolumePhase = "Ready"
+	// SharedVolumeLost means the PersistentVolumeClaim has lost its PersistentVolume, or vice
+	// versa, and we weren't able to recover automatically. Pods can't use the claim.
+	SharedVolumeLost SharedVolumePhase = "Lost"
+	// SharedVolumeRecovering means one of the PersistentVolume or PersistentVolumeClaim was
+	// deleted out of band, leaving the other unusable, so we're deleting what's left of the pair
+	// in order to recreate both. SharedVolume.Message describes the progress.
+	SharedVolumeRecovering SharedVolumePhase = "Recovering"
 	// SharedVolumeDeleting means we've noticed a deletion timestamp and have started to finalize;
 	// that is, delete the associated resources. There is no phase indicating that we've finished
 	// doing that; we expect the SharedVolume to disappear (be garbage collected) shortly.
@@ -44,6 +169,23 @@ const (
 	SharedVolumeFailed SharedVolumePhase = "Failed"
 )
 
+// Condition types reported in `SharedVolumeStatus.Conditions`
+const (
+	// SharedVolumePVCreated is True when the PersistentVolume for the SharedVolume has been created.
+	SharedVolumePVCreated = "PVCreated"
+	// SharedVolumePVCCreated is True when the PersistentVolumeClaim for the SharedVolume has been
+	// created.
+	SharedVolumePVCCreated = "PVCCreated"
+	// SharedVolumeBound is True when the PersistentVolumeClaim is bound to the PersistentVolume,
+	// i.e. when pods can actually use it.
+	SharedVolumeBound = "Bound"
+	// SharedVolumeInUse is True when at least one running pod uses the PersistentVolumeClaim.
+	SharedVolumeInUse = "InUse"
+	// SharedVolumeDegraded is True when something went wrong reconciling the SharedVolume. The
+	// condition's Reason and Message say what.
+	SharedVolumeDegraded = "Degraded"
+)
+
 // SharedVolumeStatus defines the observed state of SharedVolume
 type SharedVolumeStatus struct {
 	// Important: Run "operator-sdk generate k8s" and "... crds" to regenerate code after modifying this file
@@ -57,6 +199,37 @@ type SharedVolumeStatus struct {
 	Phase SharedVolumePhase `json:"phase,omitempty"`
 	// Message is a human-readable string, usually describing what went wrong when `Phase` is `SharedVolumeFailed`.
 	Message string `json:"message,omitempty"`
+	// AccessPointID is the ID of the access point the operator created in response to
+	// `Spec.AccessPoint`, or took from the SharedVolumeSource named by `Spec.Source`. It is empty
+	// if the SharedVolume specifies `Spec.AccessPointID`.
+	AccessPointID string `json:"accessPointID,omitempty"`
+	// FileSystemID is the ID of the EFS volume taken from the SharedVolumeSource named by
+	// `Spec.Source`. It is empty if the SharedVolume specifies `Spec.FileSystemID`.
+	// +optional
+	FileSystemID string `json:"fileSystemID,omitempty"`
+	// Conditions describe the state of the SharedVolume and its associated resources in more
+	// detail than `Phase`. See the SharedVolume* condition type consts for possible values.
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ClaimPhase mirrors the `status.phase` of the PersistentVolumeClaim.
+	// +optional
+	ClaimPhase corev1.PersistentVolumeClaimPhase `json:"claimPhase,omitempty"`
+	// VolumePhase mirrors the `status.phase` of the PersistentVolume.
+	// +optional
+	VolumePhase corev1.PersistentVolumePhase `json:"volumePhase,omitempty"`
+	// VolumeName is the name of the PersistentVolume created at the behest of this SharedVolume.
+	// It's recorded because SharedVolumes created by older versions of the operator have PVs named
+	// differently.
+	// +optional
+	VolumeName string `json:"volumeName,omitempty"`
+	// ObservedGeneration is the `metadata.generation` of the SharedVolume most recently acted on
+	// by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -66,6 +239,7 @@ type SharedVolumeStatus struct {
 // +kubebuilder:resource:path=sharedvolumes,shortName=sv,scope=Namespaced
 // +kubebuilder:printcolumn:name="File System",type=string,JSONPath=`.spec.fileSystemID`
 // +kubebuilder:printcolumn:name="Access Point",type=string,JSONPath=`.spec.accessPointID`
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.source`,priority=1
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Claim",type=string,JSONPath=`.status.claimRef.name`
 // +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`
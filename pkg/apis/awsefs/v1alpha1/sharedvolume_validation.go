@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ReservedLabelPrefix is the prefix of the labels the operator uses to keep track of the
+// resources it owns. Users mustn't be able to set them via ClaimLabels.
+const ReservedLabelPrefix = "openshift.io/aws-efs-operator"
+
+// Validate checks the consistency of a SharedVolumeSpec beyond what can be expressed in the
+// OpenAPI schema of the CRD. It is used both by the validating webhook and by the controller,
+// since the latter can't count on the former being in play.
+func (spec *SharedVolumeSpec) Validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Source != "" {
+		// The source supplies the file system and access point.
+		if spec.FileSystemID != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("fileSystemID"),
+				"may not be specified together with source"))
+		}
+		if spec.AccessPointID != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("accessPointID"),
+				"may not be specified together with source"))
+		}
+		if spec.AccessPoint != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("accessPoint"),
+				"may not be specified together with source"))
+		}
+		for _, msg := range apivalidation.NameIsDNSSubdomain(spec.Source, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("source"), spec.Source, msg))
+		}
+	} else {
+		if spec.FileSystemID == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("fileSystemID"),
+				"must be specified unless source is"))
+		}
+		if spec.AccessPointID == "" && spec.AccessPoint == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("accessPointID"),
+				"one of accessPointID, accessPoint or source must be specified"))
+		}
+		if spec.AccessPointID != "" && spec.AccessPoint != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("accessPoint"),
+				"may not be specified together with accessPointID"))
+		}
+	}
+	if spec.IAMAuthorization && !spec.EncryptInTransit {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("iamAuthorization"), spec.IAMAuthorization,
+			"requires encryptInTransit"))
+	}
+	if spec.ClaimName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(spec.ClaimName, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("claimName"), spec.ClaimName, msg))
+		}
+	}
+	if spec.StorageClassName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(spec.StorageClassName, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storageClassName"), spec.StorageClassName, msg))
+		}
+	}
+	labelsPath := fldPath.Child("claimLabels")
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.ClaimLabels, labelsPath)...)
+	for key := range spec.ClaimLabels {
+		if strings.HasPrefix(key, ReservedLabelPrefix) {
+			allErrs = append(allErrs, field.Invalid(labelsPath.Key(key), key, "is reserved for the operator's use"))
+		}
+	}
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(spec.ClaimAnnotations, fldPath.Child("claimAnnotations"))...)
+	return allErrs
+}
@@ -0,0 +1,100 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// AllowedVolume describes a file system, and optionally which of its access points, that
+// SharedVolumes may use.
+type AllowedVolume struct {
+	// The ID of the EFS volume, e.g. `fs-0123cdef`. Required.
+	// +kubebuilder:validation:Pattern=^fs-[0-9a-f]+$
+	FileSystemID string `json:"fileSystemID"`
+	// AccessPointIDs lists the access points of the file system that may be used. If empty, any
+	// access point may be used, including ones the operator provisions in response to
+	// SharedVolume.Spec.AccessPoint.
+	// +optional
+	AccessPointIDs []string `json:"accessPointIDs,omitempty"`
+}
+
+// SharedVolumePolicySpec defines which file systems and access points the SharedVolumes in a set
+// of namespaces may use.
+type SharedVolumePolicySpec struct {
+	// Namespaces lists, by name, the namespaces to which the policy applies.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects, by label, the namespaces to which the policy applies, in addition
+	// to those listed in Namespaces. An empty selector selects all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Allowed lists the file systems and access points SharedVolumes in those namespaces may use.
+	// +optional
+	Allowed []AllowedVolume `json:"allowed,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SharedVolumePolicy is the Schema for the sharedvolumepolicies API. It lets cluster
+// administrators restrict which file systems and access points SharedVolumes may use.
+// Namespaces not selected by any policy are unrestricted. SharedVolumes in a namespace selected
+// by one or more policies may only use what at least one of those policies allows.
+// +kubebuilder:resource:path=sharedvolumepolicies,shortName=svp,scope=Cluster
+type SharedVolumePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SharedVolumePolicySpec `json:"spec,omitempty"`
+}
+
+// AppliesTo returns whether the policy applies to SharedVolumes in the `namespace`. It returns an
+// error if the NamespaceSelector is malformed.
+func (p *SharedVolumePolicy) AppliesTo(namespace *corev1.Namespace) (bool, error) {
+	for _, ns := range p.Spec.Namespaces {
+		if ns == namespace.Name {
+			return true, nil
+		}
+	}
+	if p.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(p.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// Allows returns whether the policy allows the use of the file system `fsid` and access point
+// `apid`. An empty `apid` stands for an access point the operator is to provision, which is only
+// allowed if the policy doesn't restrict the file system's access points.
+func (p *SharedVolumePolicy) Allows(fsid, apid string) bool {
+	for _, allowed := range p.Spec.Allowed {
+		if allowed.FileSystemID != fsid {
+			continue
+		}
+		if len(allowed.AccessPointIDs) == 0 {
+			return true
+		}
+		for _, id := range allowed.AccessPointIDs {
+			if apid != "" && id == apid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SharedVolumePolicyList contains a list of SharedVolumePolicy
+type SharedVolumePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedVolumePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedVolumePolicy{}, &SharedVolumePolicyList{})
+}
@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedVolumeSourceSpec defines an EFS access point published for use by SharedVolumes in
+// other namespaces.
+type SharedVolumeSourceSpec struct {
+	// The ID of the EFS volume, e.g. `fs-0123cdef`. Required.
+	// +kubebuilder:validation:Pattern=^fs-[0-9a-f]+$
+	FileSystemID string `json:"fileSystemID"`
+	// The ID of an EFS volume access point, e.g. `fsap-0123456789abcdef`. Required.
+	// +kubebuilder:validation:Pattern=^fsap-[0-9a-f]+$
+	AccessPointID string `json:"accessPointID"`
+	// AllowedNamespaces lists the namespaces whose SharedVolumes may use this source. A
+	// SharedVolume in any other namespace referring to this source will be Failed.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SharedVolumeSource is the Schema for the sharedvolumesources API. It lets cluster
+// administrators publish an EFS access point to a set of namespaces, whose SharedVolumes then
+// refer to it by name, without needing to know the EFS IDs.
+// +kubebuilder:resource:path=sharedvolumesources,shortName=svs,scope=Cluster
+// +kubebuilder:printcolumn:name="File System",type=string,JSONPath=`.spec.fileSystemID`
+// +kubebuilder:printcolumn:name="Access Point",type=string,JSONPath=`.spec.accessPointID`
+type SharedVolumeSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SharedVolumeSourceSpec `json:"spec,omitempty"`
+}
+
+// AllowsNamespace returns whether SharedVolumes in the `namespace` may use the source.
+func (s *SharedVolumeSource) AllowsNamespace(namespace string) bool {
+	for _, ns := range s.Spec.AllowedNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SharedVolumeSourceList contains a list of SharedVolumeSource
+type SharedVolumeSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedVolumeSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedVolumeSource{}, &SharedVolumeSourceList{})
+}
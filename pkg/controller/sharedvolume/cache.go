@@ -0,0 +1,69 @@
+package sharedvolume
+
+// Caches of the Ensurables for SharedVolumes' PVs and PVCs
+
+import (
+	"sync"
+
+	util "openshift/aws-efs-operator/pkg/util"
+)
+
+// ensurableCache maps SharedVolumes, by svKey, to the Ensurables of one of their resources. It's
+// safe for concurrent use by the controller's workers. Entries are evicted when the SharedVolume
+// is found to be gone (see forget), so the cache is bounded by the number of SharedVolumes.
+type ensurableCache struct {
+	mutex      sync.Mutex
+	ensurables map[string]util.Ensurable
+}
+
+func newEnsurableCache() *ensurableCache {
+	return &ensurableCache{ensurables: make(map[string]util.Ensurable)}
+}
+
+// getOrCreate returns the Ensurable cached for `key`, first caching the one `create` returns if
+// there isn't one.
+func (c *ensurableCache) getOrCreate(key string, create func() util.Ensurable) util.Ensurable {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.ensurables[key]
+	if !ok {
+		e = create()
+		c.ensurables[key] = e
+	}
+	return e
+}
+
+// get returns the Ensurable cached for `key`, if any.
+func (c *ensurableCache) get(key string) (util.Ensurable, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.ensurables[key]
+	return e, ok
+}
+
+// set caches the Ensurable `e` for `key`, replacing any already cached.
+func (c *ensurableCache) set(key string, e util.Ensurable) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ensurables[key] = e
+}
+
+// remove evicts the Ensurable cached for `key`, if any.
+func (c *ensurableCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.ensurables, key)
+}
+
+// len returns the number of cached Ensurables.
+func (c *ensurableCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.ensurables)
+}
+
+// forget evicts the Ensurables cached for the SharedVolume with the `key`, which is gone.
+func forget(key string) {
+	pvcBySharedVolume.remove(key)
+	pvBySharedVolume.remove(key)
+}
@@ -0,0 +1,136 @@
+package sharedvolume
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/test"
+	"openshift/aws-efs-operator/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnsurableCacheConcurrent(t *testing.T) {
+	c := newEnsurableCache()
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		created = make(map[string]int)
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, key := range []string{"shared", fmt.Sprintf("own-%d", i)} {
+				c.getOrCreate(key, func() util.Ensurable {
+					mutex.Lock()
+					defer mutex.Unlock()
+					created[key]++
+					return &util.EnsurableImpl{}
+				})
+			}
+			c.remove(fmt.Sprintf("own-%d", i%10))
+		}(i)
+	}
+	wg.Wait()
+
+	for key, count := range created {
+		if count != 1 {
+			t.Errorf("Expected the Ensurable for %s to be created once, but it was created %d times", key, count)
+		}
+	}
+	// The first ten were removed, unless they were created after being removed.
+	if n := c.len(); n < 41 || n > 51 {
+		t.Fatalf("Expected between 41 and 51 cached Ensurables but got %d", n)
+	}
+	e, ok := c.get("shared")
+	if !ok {
+		t.Fatal("Expected the shared Ensurable to be cached")
+	}
+	if again := c.getOrCreate("shared", func() util.Ensurable { return nil }); again != e {
+		t.Fatalf("Expected the cached Ensurable, %v, but got %v", e, again)
+	}
+}
+
+// TestReconcileConcurrent reconciles many SharedVolumes in parallel, as the controller does with
+// MaxConcurrentReconciles > 1, and then makes sure the caches are emptied as they go away.
+func TestReconcileConcurrent(t *testing.T) {
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+	const count = 20
+
+	r := fakeReconciler()
+	var svs []*awsefsv1alpha1.SharedVolume
+	for i := 0; i < count; i++ {
+		sv := &awsefsv1alpha1.SharedVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("sv-%d", i),
+				Namespace: fmt.Sprintf("ns-%d", i%3),
+			},
+			Spec: awsefsv1alpha1.SharedVolumeSpec{
+				AccessPointID: "fsap-1234abcd",
+				FileSystemID:  "fs-1234abcd",
+			},
+		}
+		if err := r.client.Create(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+		svs = append(svs, sv)
+	}
+
+	// reconcileAll reconciles each SharedVolume, in parallel, until it's settled.
+	reconcileAll := func() {
+		var wg sync.WaitGroup
+		for _, sv := range svs {
+			wg.Add(1)
+			go func(sv *awsefsv1alpha1.SharedVolume) {
+				defer wg.Done()
+				req := makeRequest(t, sv)
+				for i := 0; i < 10; i++ {
+					res, err := r.Reconcile(ctx, req)
+					if err != nil {
+						t.Errorf("Reconcile of %s failed: %v", req.NamespacedName, err)
+						return
+					}
+					if res == test.NullResult {
+						return
+					}
+				}
+				t.Errorf("Reconcile of %s didn't settle", req.NamespacedName)
+			}(sv)
+		}
+		wg.Wait()
+	}
+
+	reconcileAll()
+	validateResources(t, r.client, count)
+	if n, m := pvBySharedVolume.len(), pvcBySharedVolume.len(); n != count || m != count {
+		t.Fatalf("Expected %d cached PV and PVC Ensurables but got %d and %d", count, n, m)
+	}
+
+	// Marking a SharedVolume for deletion gets it finalized by handleDelete, which drops its
+	// Ensurables...
+	delTime := metav1.Now()
+	for _, sv := range svs[:count/2] {
+		if err := r.client.Get(ctx, makeRequest(t, sv).NamespacedName, sv); err != nil {
+			t.Fatal(err)
+		}
+		sv.DeletionTimestamp = &delTime
+		if err := r.client.Update(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ...but a SharedVolume can also vanish without our finalizer running, e.g. if it's removed
+	// by hand.
+	for _, sv := range svs[count/2:] {
+		if err := r.client.Delete(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reconcileAll()
+	if n, m := pvBySharedVolume.len(), pvcBySharedVolume.len(); n != 0 || m != 0 {
+		t.Fatalf("Expected the caches to be empty but they have %d PV and %d PVC Ensurables", n, m)
+	}
+}
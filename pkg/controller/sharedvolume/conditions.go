@@ -0,0 +1,206 @@
+package sharedvolume
+
+// Helpers for maintaining SharedVolume Status.Conditions
+
+import (
+	"context"
+	"fmt"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Condition Reasons. These are CamelCase per the metav1.Condition contract.
+const (
+	reasonPending              = "Pending"
+	reasonCreated              = "Created"
+	reasonEnsureFailed         = "EnsureFailed"
+	reasonInvalidSpec          = "InvalidSpec"
+	reasonProvisionFailed      = "AccessPointProvisioningFailed"
+	reasonAsExpected           = "AsExpected"
+	reasonClaimBound           = "ClaimBound"
+	reasonClaimNotBound        = "ClaimNotBound"
+	reasonClaimLost            = "ClaimLost"
+	reasonVolumeLost           = "VolumeLost"
+	reasonRecovering           = "Recovering"
+	reasonClaimNameConflict    = "ClaimNameConflict"
+	reasonVolumeNameConflict   = "VolumeNameConflict"
+	reasonSourceNotFound       = "SourceNotFound"
+	reasonSourceNotAllowed     = "SourceNotAllowed"
+	reasonPolicyViolation      = "PolicyViolation"
+	reasonStorageClassNotFound = "StorageClassNotFound"
+	reasonStorageClassInvalid  = "StorageClassInvalid"
+	reasonPodsUsingClaim       = "PodsUsingClaim"
+	reasonNoPodsUsingClaim     = "NoPodsUsingClaim"
+)
+
+// newCondition is a shorthand for building a metav1.Condition. The ObservedGeneration and
+// LastTransitionTime are filled in by setCondition.
+func newCondition(condType string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    condType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// degraded returns a True Degraded condition with the given `reason` and `message`.
+func degraded(reason, message string) metav1.Condition {
+	return newCondition(awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reason, message)
+}
+
+// pendingConditions are the conditions of a SharedVolume whose resources we haven't created yet.
+func pendingConditions() []metav1.Condition {
+	return []metav1.Condition{
+		newCondition(awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionFalse, reasonPending, ""),
+		newCondition(awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionFalse, reasonPending, ""),
+		newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonPending, ""),
+		newCondition(awsefsv1alpha1.SharedVolumeInUse, metav1.ConditionFalse, reasonPending, ""),
+		newCondition(awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected, ""),
+	}
+}
+
+// setCondition sets `condition` in the `sharedVolume`'s Status.Conditions, stamping it with the
+// SharedVolume's current Generation. The LastTransitionTime only changes if the condition's
+// Status does. The return indicates whether anything changed.
+func setCondition(sharedVolume *awsefsv1alpha1.SharedVolume, condition metav1.Condition) bool {
+	condition.ObservedGeneration = sharedVolume.Generation
+	existing := meta.FindStatusCondition(sharedVolume.Status.Conditions, condition.Type)
+	if existing != nil &&
+		existing.Status == condition.Status &&
+		existing.Reason == condition.Reason &&
+		existing.Message == condition.Message &&
+		existing.ObservedGeneration == condition.ObservedGeneration {
+		return false
+	}
+	meta.SetStatusCondition(&sharedVolume.Status.Conditions, condition)
+	return true
+}
+
+// setConditions sets each of the `conditions` (see setCondition) and the Status.ObservedGeneration
+// of the `sharedVolume`. The return indicates whether anything changed.
+func setConditions(sharedVolume *awsefsv1alpha1.SharedVolume, conditions ...metav1.Condition) bool {
+	changed := false
+	for _, condition := range conditions {
+		if setCondition(sharedVolume, condition) {
+			changed = true
+		}
+	}
+	if sharedVolume.Status.ObservedGeneration != sharedVolume.Generation {
+		sharedVolume.Status.ObservedGeneration = sharedVolume.Generation
+		changed = true
+	}
+	return changed
+}
+
+// bindingStatus inspects the `pvc` and `pv` (either of which may be nil if we couldn't find it)
+// and returns the SharedVolume Phase, Message, and Bound and Degraded conditions they imply.
+func bindingStatus(pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) (
+	awsefsv1alpha1.SharedVolumePhase, string, metav1.Condition, metav1.Condition) {
+
+	notDegraded := newCondition(awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected, "")
+	if pvc == nil || pv == nil {
+		// We just created them, and they haven't shown up in the cache yet.
+		return awsefsv1alpha1.SharedVolumeBinding, "",
+			newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonPending, ""), notDegraded
+	}
+
+	var message, reason string
+	switch {
+	case pvc.Status.Phase == corev1.ClaimLost:
+		message = fmt.Sprintf("PersistentVolumeClaim %s is %s", pvc.Name, pvc.Status.Phase)
+		reason = reasonClaimLost
+	case pv.Status.Phase == corev1.VolumeReleased || pv.Status.Phase == corev1.VolumeFailed:
+		message = fmt.Sprintf("PersistentVolume %s is %s", pv.Name, pv.Status.Phase)
+		reason = reasonVolumeLost
+	}
+	if reason != "" {
+		return awsefsv1alpha1.SharedVolumeLost, message,
+			newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reason, message),
+			degraded(reason, message)
+	}
+
+	if pvc.Status.Phase == corev1.ClaimBound && pv.Status.Phase == corev1.VolumeBound {
+		return awsefsv1alpha1.SharedVolumeReady, "",
+			newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionTrue, reasonClaimBound, ""), notDegraded
+	}
+
+	// Anything else (e.g. Pending, or the two sides of the binding not yet agreeing) is in flux.
+	message = fmt.Sprintf("PersistentVolumeClaim %s is %s; PersistentVolume %s is %s",
+		pvc.Name, phaseOrUnknown(string(pvc.Status.Phase)), pv.Name, phaseOrUnknown(string(pv.Status.Phase)))
+	return awsefsv1alpha1.SharedVolumeBinding, message,
+		newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonClaimNotBound, message), notDegraded
+}
+
+// phaseOrUnknown returns `phase`, or "Unknown" if it's empty, which it is until the PV controller
+// gets around to the resource.
+func phaseOrUnknown(phase string) string {
+	if phase == "" {
+		return "Unknown"
+	}
+	return phase
+}
+
+// setBindingPhases mirrors the phases of the `pvc` and `pv` (either of which may be nil) into the
+// `sharedVolume`'s Status, returning whether anything changed.
+func setBindingPhases(sharedVolume *awsefsv1alpha1.SharedVolume, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) bool {
+	var claimPhase corev1.PersistentVolumeClaimPhase
+	var volumePhase corev1.PersistentVolumePhase
+	if pvc != nil {
+		claimPhase = pvc.Status.Phase
+	}
+	if pv != nil {
+		volumePhase = pv.Status.Phase
+	}
+	if sharedVolume.Status.ClaimPhase == claimPhase && sharedVolume.Status.VolumePhase == volumePhase {
+		return false
+	}
+	sharedVolume.Status.ClaimPhase = claimPhase
+	sharedVolume.Status.VolumePhase = volumePhase
+	return true
+}
+
+// inUseCondition returns the InUse condition according to whether any non-terminated pods in the
+// PVC's namespace use the PVC at `pvcnsname`. The pods are listed via `c`, which should bypass the
+// cache: it only holds the pods' metadata, which doesn't say which PVCs they use.
+func inUseCondition(ctx context.Context, c client.Reader, pvcnsname types.NamespacedName) (metav1.Condition, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(pvcnsname.Namespace)); err != nil {
+		return metav1.Condition{}, err
+	}
+	numPods := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, claimName := range claimNames(pod) {
+			if claimName == pvcnsname.Name {
+				numPods++
+				break
+			}
+		}
+	}
+	if numPods == 0 {
+		return newCondition(awsefsv1alpha1.SharedVolumeInUse, metav1.ConditionFalse, reasonNoPodsUsingClaim, ""), nil
+	}
+	return newCondition(awsefsv1alpha1.SharedVolumeInUse, metav1.ConditionTrue, reasonPodsUsingClaim,
+		fmt.Sprintf("PersistentVolumeClaim %s is used by %d pod(s)", pvcnsname.Name, numPods)), nil
+}
+
+// claimNames returns the names of the PVCs used by the `pod`'s volumes.
+func claimNames(pod *corev1.Pod) []string {
+	names := []string{}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			names = append(names, vol.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return names
+}
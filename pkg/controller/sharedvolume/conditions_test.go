@@ -0,0 +1,318 @@
+package sharedvolume
+
+import (
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/test"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkCondition fails the `t`est if the `sv` doesn't have a condition of type `condType` with
+// the given `status` and `reason`.
+func checkCondition(t *testing.T, sv *awsefsv1alpha1.SharedVolume, condType string, status metav1.ConditionStatus, reason string) {
+	t.Helper()
+	cond := meta.FindStatusCondition(sv.Status.Conditions, condType)
+	if cond == nil {
+		t.Fatalf("Expected condition %s but got %s", condType, format(sv.Status.Conditions))
+	}
+	if cond.Status != status || cond.Reason != reason {
+		t.Fatalf("Expected condition %s to be %s with reason %s but got %s", condType, status, reason, format(cond))
+	}
+	if cond.ObservedGeneration != sv.Generation {
+		t.Fatalf("Expected condition %s to have ObservedGeneration %d but got %d",
+			condType, sv.Generation, cond.ObservedGeneration)
+	}
+}
+
+func mkPod(name, claimName string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "proj1",
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	sv := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+
+	// New condition
+	if !setConditions(sv, newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonPending, "")) {
+		t.Fatal("Expected a change")
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonPending)
+	if sv.Status.ObservedGeneration != 3 {
+		t.Fatalf("Expected ObservedGeneration 3 but got %d", sv.Status.ObservedGeneration)
+	}
+	transitioned := meta.FindStatusCondition(sv.Status.Conditions, awsefsv1alpha1.SharedVolumeBound).LastTransitionTime
+	if transitioned.IsZero() {
+		t.Fatal("Expected LastTransitionTime to be set")
+	}
+
+	// Same again is a no-op
+	if setConditions(sv, newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonPending, "")) {
+		t.Fatal("Expected no change")
+	}
+
+	// Changing the reason (but not the status) is a change, but not a transition
+	if !setConditions(sv, newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonClaimNotBound, "msg")) {
+		t.Fatal("Expected a change")
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonClaimNotBound)
+	if cond := meta.FindStatusCondition(sv.Status.Conditions, awsefsv1alpha1.SharedVolumeBound); cond.LastTransitionTime != transitioned {
+		t.Fatalf("Expected LastTransitionTime to stay %v but got %v", transitioned, cond.LastTransitionTime)
+	}
+
+	// A new generation is a change, even with no conditions
+	sv.Generation = 4
+	if !setConditions(sv) {
+		t.Fatal("Expected a change")
+	}
+	if sv.Status.ObservedGeneration != 4 {
+		t.Fatalf("Expected ObservedGeneration 4 but got %d", sv.Status.ObservedGeneration)
+	}
+}
+
+func TestBindingStatus(t *testing.T) {
+	mkPVC := func(phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-sv", Namespace: "proj1"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	mkPV := func(phase corev1.PersistentVolumePhase) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-proj1-sv-ee8069eb38"},
+			Status:     corev1.PersistentVolumeStatus{Phase: phase},
+		}
+	}
+	tests := []struct {
+		name          string
+		pvc           *corev1.PersistentVolumeClaim
+		pv            *corev1.PersistentVolume
+		phase         awsefsv1alpha1.SharedVolumePhase
+		message       string
+		boundStatus   metav1.ConditionStatus
+		boundReason   string
+		degradedState metav1.ConditionStatus
+	}{
+		{"not cached yet", nil, mkPV(corev1.VolumeAvailable), awsefsv1alpha1.SharedVolumeBinding, "",
+			metav1.ConditionFalse, reasonPending, metav1.ConditionFalse},
+		{"no phases yet", mkPVC(""), mkPV(""), awsefsv1alpha1.SharedVolumeBinding,
+			"PersistentVolumeClaim pvc-sv is Unknown; PersistentVolume pv-proj1-sv-ee8069eb38 is Unknown",
+			metav1.ConditionFalse, reasonClaimNotBound, metav1.ConditionFalse},
+		{"pending", mkPVC(corev1.ClaimPending), mkPV(corev1.VolumeAvailable), awsefsv1alpha1.SharedVolumeBinding,
+			"PersistentVolumeClaim pvc-sv is Pending; PersistentVolume pv-proj1-sv-ee8069eb38 is Available",
+			metav1.ConditionFalse, reasonClaimNotBound, metav1.ConditionFalse},
+		{"half bound", mkPVC(corev1.ClaimBound), mkPV(corev1.VolumeAvailable), awsefsv1alpha1.SharedVolumeBinding,
+			"PersistentVolumeClaim pvc-sv is Bound; PersistentVolume pv-proj1-sv-ee8069eb38 is Available",
+			metav1.ConditionFalse, reasonClaimNotBound, metav1.ConditionFalse},
+		{"bound", mkPVC(corev1.ClaimBound), mkPV(corev1.VolumeBound), awsefsv1alpha1.SharedVolumeReady, "",
+			metav1.ConditionTrue, reasonClaimBound, metav1.ConditionFalse},
+		{"claim lost", mkPVC(corev1.ClaimLost), mkPV(corev1.VolumeBound), awsefsv1alpha1.SharedVolumeLost,
+			"PersistentVolumeClaim pvc-sv is Lost", metav1.ConditionFalse, reasonClaimLost, metav1.ConditionTrue},
+		{"volume released", mkPVC(corev1.ClaimBound), mkPV(corev1.VolumeReleased), awsefsv1alpha1.SharedVolumeLost,
+			"PersistentVolume pv-proj1-sv-ee8069eb38 is Released", metav1.ConditionFalse, reasonVolumeLost, metav1.ConditionTrue},
+		{"volume failed", mkPVC(corev1.ClaimPending), mkPV(corev1.VolumeFailed), awsefsv1alpha1.SharedVolumeLost,
+			"PersistentVolume pv-proj1-sv-ee8069eb38 is Failed", metav1.ConditionFalse, reasonVolumeLost, metav1.ConditionTrue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase, message, bound, degradedCond := bindingStatus(tt.pvc, tt.pv)
+			if phase != tt.phase || message != tt.message {
+				t.Fatalf("Expected phase %q, message %q but got %q, %q", tt.phase, tt.message, phase, message)
+			}
+			if bound.Type != awsefsv1alpha1.SharedVolumeBound || bound.Status != tt.boundStatus || bound.Reason != tt.boundReason {
+				t.Fatalf("Expected Bound %s/%s but got %s", tt.boundStatus, tt.boundReason, format(bound))
+			}
+			if degradedCond.Type != awsefsv1alpha1.SharedVolumeDegraded || degradedCond.Status != tt.degradedState {
+				t.Fatalf("Expected Degraded %s but got %s", tt.degradedState, format(degradedCond))
+			}
+		})
+	}
+}
+
+func TestSetBindingPhases(t *testing.T) {
+	sv := &awsefsv1alpha1.SharedVolume{}
+	if setBindingPhases(sv, nil, nil) {
+		t.Fatal("Expected no change")
+	}
+	pvc := &corev1.PersistentVolumeClaim{Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}}
+	pv := &corev1.PersistentVolume{Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable}}
+	if !setBindingPhases(sv, pvc, pv) {
+		t.Fatal("Expected a change")
+	}
+	if sv.Status.ClaimPhase != corev1.ClaimPending || sv.Status.VolumePhase != corev1.VolumeAvailable {
+		t.Fatalf("Expected Pending/Available but got %s/%s", sv.Status.ClaimPhase, sv.Status.VolumePhase)
+	}
+	if setBindingPhases(sv, pvc, pv) {
+		t.Fatal("Expected no change")
+	}
+}
+
+func TestInUseCondition(t *testing.T) {
+	r := fakeReconciler()
+	pvcnsname := types.NamespacedName{Namespace: "proj1", Name: "pvc-sv"}
+
+	// No pods at all
+	cond, err := inUseCondition(ctx, r.client, pvcnsname)
+	if err != nil || cond.Status != metav1.ConditionFalse || cond.Reason != reasonNoPodsUsingClaim {
+		t.Fatalf("Expected False/%s, no error but got %s\nerr: %v", reasonNoPodsUsingClaim, format(cond), err)
+	}
+
+	// Pods that don't count: using a different claim, or finished
+	for _, pod := range []*corev1.Pod{
+		mkPod("other", "pvc-other", corev1.PodRunning),
+		mkPod("done", pvcnsname.Name, corev1.PodSucceeded),
+		mkPod("dead", pvcnsname.Name, corev1.PodFailed),
+	} {
+		if err = r.client.Create(ctx, pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cond, err = inUseCondition(ctx, r.client, pvcnsname)
+	if err != nil || cond.Status != metav1.ConditionFalse || cond.Reason != reasonNoPodsUsingClaim {
+		t.Fatalf("Expected False/%s, no error but got %s\nerr: %v", reasonNoPodsUsingClaim, format(cond), err)
+	}
+
+	// Pods that do
+	for _, pod := range []*corev1.Pod{
+		mkPod("running", pvcnsname.Name, corev1.PodRunning),
+		mkPod("starting", pvcnsname.Name, corev1.PodPending),
+	} {
+		if err = r.client.Create(ctx, pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cond, err = inUseCondition(ctx, r.client, pvcnsname)
+	if err != nil || cond.Status != metav1.ConditionTrue || cond.Reason != reasonPodsUsingClaim ||
+		cond.Message != "PersistentVolumeClaim pvc-sv is used by 2 pod(s)" {
+		t.Fatalf("Expected True/%s, no error but got %s\nerr: %v", reasonPodsUsingClaim, format(cond), err)
+	}
+}
+
+// TestConditionsReconcile walks a SharedVolume through Reconcile, checking its conditions.
+func TestConditionsReconcile(t *testing.T) {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	// Nobody binds the PV and PVC until we say so.
+	r := unbound(fakeReconciler())
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID: "fsap-abc123abc123",
+			FileSystemID:  "fs-123abc",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+
+	// Finalizer, then Pending
+	for i := 0; i < 2; i++ {
+		if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+			t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+	svMap, _, _ := getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	for _, condType := range []string{
+		awsefsv1alpha1.SharedVolumePVCreated,
+		awsefsv1alpha1.SharedVolumePVCCreated,
+		awsefsv1alpha1.SharedVolumeBound,
+		awsefsv1alpha1.SharedVolumeInUse,
+	} {
+		checkCondition(t, sv, condType, metav1.ConditionFalse, reasonPending)
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected)
+
+	// Create the PV and PVC. They aren't bound yet, so we're Binding, and keep checking back.
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeBinding || sv.Status.ClaimRef.Name != "pvc-sv" {
+		t.Fatalf("Expected Binding phase and ClaimRef pvc-sv but got %s", format(sv.Status))
+	}
+	if sv.Status.Message != "PersistentVolumeClaim pvc-sv is Unknown; PersistentVolume pv-proj1-sv-ee8069eb38 is Unknown" {
+		t.Fatalf("Unexpected message %q", sv.Status.Message)
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionTrue, reasonCreated)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionTrue, reasonCreated)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonClaimNotBound)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeInUse, metav1.ConditionFalse, reasonNoPodsUsingClaim)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected)
+
+	// Play the part of the PV controller and bind the PV and PVC; and start a pod using it.
+	pv := pvMap["/pv-proj1-sv-ee8069eb38"]
+	pv.Status.Phase = corev1.VolumeBound
+	if err := r.client.Update(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	pvc := pvcMap["proj1/pvc-sv"]
+	pvc.Status.Phase = corev1.ClaimBound
+	if err := r.client.Update(ctx, pvc); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.client.Create(ctx, mkPod("pod", pvc.Name, corev1.PodRunning)); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = validateResources(t, r.client, 1)
+	sv = svMap["proj1/sv"]
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeBound, metav1.ConditionTrue, reasonClaimBound)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeInUse, metav1.ConditionTrue, reasonPodsUsingClaim)
+	if sv.Status.Message != "" || sv.Status.ClaimPhase != corev1.ClaimBound || sv.Status.VolumePhase != corev1.VolumeBound {
+		t.Fatalf("Expected no message and Bound phases but got %s", format(sv.Status))
+	}
+}
+
+func TestPodToSharedVolumes(t *testing.T) {
+	r := fakeReconciler()
+	for _, nsname := range []types.NamespacedName{{Namespace: "proj1", Name: "sv1"}, {Namespace: "proj1", Name: "sv2"},
+		{Namespace: "proj2", Name: "sv3"}} {
+		if err := r.client.Create(ctx, &awsefsv1alpha1.SharedVolume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nsname.Namespace, Name: nsname.Name},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mapper := podToSharedVolumes(r.client)
+
+	// We only see the pod's metadata
+	pod := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "proj3", Name: "pod"}}
+	if reqs := mapper(pod); len(reqs) != 0 {
+		t.Fatalf("Expected no requests but got %v", reqs)
+	}
+
+	pod.Namespace = "proj1"
+	reqs := mapper(pod)
+	if len(reqs) != 2 || reqs[0].Namespace != "proj1" || reqs[1].Namespace != "proj1" {
+		t.Fatalf("Expected requests for proj1/sv1 and proj1/sv2 but got %v", reqs)
+	}
+}
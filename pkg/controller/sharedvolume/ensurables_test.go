@@ -4,6 +4,8 @@ package sharedvolume
 
 import (
 	"fmt"
+	"strings"
+
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
 	"openshift/aws-efs-operator/pkg/test"
 	util "openshift/aws-efs-operator/pkg/util"
@@ -12,8 +14,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -36,13 +38,8 @@ var sharedVolume = awsefsv1alpha1.SharedVolume{
 
 // validateSharedVolumeOwner makes sure that `toSharedVolume` on `def` returns a `Request` that points
 // to `sharedVolume`, proving that `def` was created using `setSharedVolumeOwner`, and that worked.
-func validateSharedVolumeOwner(t *testing.T, def runtime.Object, sharedVolume *awsefsv1alpha1.SharedVolume) {
-	// To run toSharedVolume, we have to create a MapObject
-	mo := handler.MapObject{
-		Meta:   def.(metav1.Object),
-		Object: def,
-	}
-	rqList := toSharedVolume(mo)
+func validateSharedVolumeOwner(t *testing.T, def client.Object, sharedVolume *awsefsv1alpha1.SharedVolume) {
+	rqList := toSharedVolume(def)
 	if len(rqList) != 1 {
 		t.Fatalf("Expected one Request, got %d: %v", len(rqList), rqList)
 	}
@@ -113,6 +110,38 @@ func TestPVCEnsurable(t *testing.T) {
 	}
 }
 
+// TestClaimNameLabelsAnnotations makes sure the claim name, labels and annotations requested in
+// the SharedVolume make it into the PV and PVC definitions, and that the owner labels win.
+func TestClaimNameLabelsAnnotations(t *testing.T) {
+	sv := sharedVolume.DeepCopy()
+	sv.Name = "claimed"
+	sv.Spec.ClaimName = "data"
+	sv.Spec.ClaimLabels = map[string]string{"backup": "daily"}
+	sv.Spec.ClaimAnnotations = map[string]string{"example.com/owner": "team-a"}
+
+	pvc := pvcEnsurable(sv).(*util.EnsurableImpl).Definition.(*corev1.PersistentVolumeClaim)
+	pv := pvEnsurable(sv).(*util.EnsurableImpl).Definition.(*corev1.PersistentVolume)
+	if pvc.Name != "data" || pvc.Namespace != fakeNamespace {
+		t.Fatalf("Expected PVC %s/data but got %s/%s", fakeNamespace, pvc.Namespace, pvc.Name)
+	}
+	if pv.Name != "pv-project1-claimed-06017167ae" {
+		t.Fatalf("Expected the PV name not to be affected by the claim name, but got %s", pv.Name)
+	}
+	for _, obj := range []metav1.Object{pvc, pv} {
+		labels := obj.GetLabels()
+		if labels["backup"] != "daily" || labels[svOwnerNamespaceKey] != fakeNamespace || labels[svOwnerNameKey] != "claimed" {
+			t.Fatalf("Expected requested and owner labels on %s but got %v", obj.GetName(), labels)
+		}
+		if annotations := obj.GetAnnotations(); annotations["example.com/owner"] != "team-a" {
+			t.Fatalf("Expected requested annotations on %s but got %v", obj.GetName(), annotations)
+		}
+	}
+	// The SharedVolume's own maps must not have been touched
+	if len(sv.Spec.ClaimLabels) != 1 {
+		t.Fatalf("Expected the SharedVolume's ClaimLabels to be left alone but got %v", sv.Spec.ClaimLabels)
+	}
+}
+
 // TestCache is because I originally had a bug in how I was keying the caches. Makes sure that
 // we don't collide if we have SharedVolumes with the same name in different namespaces.
 func TestCache(t *testing.T) {
@@ -147,26 +176,60 @@ func TestCache(t *testing.T) {
 
 	// Same thing with PVCs
 	pvc1 := pvcEnsurable(&sharedVolume).(*util.EnsurableImpl).Definition.(*corev1.PersistentVolumeClaim)
-	if pvc1.Spec.VolumeName != "pv-project1-my-shared-volume" {
+	if pvc1.Spec.VolumeName != "pv-project1-my-shared-volume-e54dbf7476" {
 		t.Fatalf("Expected PVC ensurable to correspond to\nSharedVolume %v\nbut got\nPVC %v",
 			format(sharedVolume), format(pvc1))
 	}
 	pvc2 := pvcEnsurable(&sv2).(*util.EnsurableImpl).Definition.(*corev1.PersistentVolumeClaim)
-	if pvc2.Spec.VolumeName != "pv-project2-my-shared-volume" {
+	if pvc2.Spec.VolumeName != "pv-project2-my-shared-volume-5eb825a39a" {
 		t.Fatalf("Expected PVC ensurable to correspond to\nSharedVolume %v\nbut got\nPVC %v",
 			format(sv2), format(pvc2))
 	}
 }
 
+// TestPVName makes sure PV names can't collide across SharedVolumes whose namespace and name
+// happen to concatenate the same way, and never exceed the length limit.
+func TestPVName(t *testing.T) {
+	mk := func(namespace, name string) *awsefsv1alpha1.SharedVolume {
+		return &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	// Under the old scheme, these were both pv-a-b-c
+	n1 := newPVName(mk("a-b", "c"))
+	n2 := newPVName(mk("a", "b-c"))
+	if n1 == n2 {
+		t.Fatalf("Expected distinct PV names but both were %s", n1)
+	}
+	if !strings.HasPrefix(n1, "pv-a-b-c-") {
+		t.Fatalf("Expected PV name to retain the legacy name as a prefix but got %s", n1)
+	}
+
+	// Namespaces are limited to 63 characters; names to 253.
+	long := mk(strings.Repeat("n", 63), strings.Repeat("s", 252)+".")
+	name := newPVName(long)
+	if len(name) != validation.DNS1123SubdomainMaxLength {
+		t.Fatalf("Expected PV name to be truncated to %d characters but got %d: %s",
+			validation.DNS1123SubdomainMaxLength, len(name), name)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+		t.Fatalf("Expected a valid PV name but got %s: %v", name, errs)
+	}
+	// Truncation mustn't make names collide either
+	if other := newPVName(mk(long.Namespace, strings.Repeat("s", 253))); other == name {
+		t.Fatalf("Expected distinct PV names but both were %s", name)
+	}
+
+	// Once recorded, the name in the Status wins.
+	sv := mk("a", "b-c")
+	sv.Status.VolumeName = "pv-a-b-c"
+	if name = pvNameForSharedVolume(sv); name != "pv-a-b-c" {
+		t.Fatalf("Expected the recorded PV name but got %s", name)
+	}
+}
+
 // TestToSharedVolume validates the path where an event arrives for an object that passes
 // ICarePredicate but doesn't have pointers to a SharedVolume.
 func TestToSharedVolumeUnlabeled(t *testing.T) {
-	o := &corev1.Pod{}
-	mo := handler.MapObject{
-		Meta:   o,
-		Object: o,
-	}
-	rqList := toSharedVolume(mo)
+	rqList := toSharedVolume(&corev1.Pod{})
 	if len(rqList) != 0 {
 		t.Fatalf("Expected no Request, got %v", rqList)
 	}
@@ -0,0 +1,52 @@
+package sharedvolume
+
+// Helpers for emitting Events against SharedVolumes. These are what app teams see via
+// `oc describe sv`, since they generally don't have access to the operator's logs.
+
+import (
+	"fmt"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Event Reasons, in addition to the SharedVolumePhases, which are used as the Reasons of the
+// Events recording transitions between them. (Creation, update and deletion of the PV and PVC is
+// recorded by their Ensurables, with the util.EventReason* Reasons.)
+const (
+	eventReasonFinalizerRegistered     = "FinalizerRegistered"
+	eventReasonSpecReverted            = "SpecReverted"
+	eventReasonAccessPointCreated      = "AccessPointCreated"
+	eventReasonAccessPointDeleted      = "AccessPointDeleted"
+	eventReasonAccessPointDeleteFailed = "AccessPointDeleteFailed"
+)
+
+// phaseEventType returns the type of Event to record for a transition to `phase`.
+func phaseEventType(phase awsefsv1alpha1.SharedVolumePhase) string {
+	switch phase {
+	case awsefsv1alpha1.SharedVolumeFailed, awsefsv1alpha1.SharedVolumeLost, awsefsv1alpha1.SharedVolumeRecovering:
+		return corev1.EventTypeWarning
+	}
+	return corev1.EventTypeNormal
+}
+
+// phaseEventNeeded decides whether changing the `sharedVolume`'s Status from its current Phase and
+// Message to `phase` and `message` warrants an Event. Every change of Phase does; for the Warning
+// phases, so does a change of Message, since it's likely to describe a different problem.
+func phaseEventNeeded(sharedVolume *awsefsv1alpha1.SharedVolume, phase awsefsv1alpha1.SharedVolumePhase, message string) bool {
+	if sharedVolume.Status.Phase != phase {
+		return true
+	}
+	return phaseEventType(phase) == corev1.EventTypeWarning && sharedVolume.Status.Message != message
+}
+
+// recordPhase emits an Event for the `sharedVolume` having moved to its current Status.Phase.
+func (r *ReconcileSharedVolume) recordPhase(sharedVolume *awsefsv1alpha1.SharedVolume) {
+	phase := sharedVolume.Status.Phase
+	message := sharedVolume.Status.Message
+	if message == "" {
+		message = fmt.Sprintf("SharedVolume is %s", phase)
+	}
+	r.recorder.Event(sharedVolume, phaseEventType(phase), string(phase), message)
+}
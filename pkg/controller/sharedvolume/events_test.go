@@ -0,0 +1,108 @@
+package sharedvolume
+
+import (
+	"reflect"
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/metrics"
+	"openshift/aws-efs-operator/pkg/test"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// checkEvents reconciles the `sv` and fails the `t`est if that doesn't produce exactly the `expected`
+// Events.
+func checkEvents(t *testing.T, r *ReconcileSharedVolume, sv *awsefsv1alpha1.SharedVolume, expected ...string) {
+	t.Helper()
+	if _, err := r.Reconcile(ctx, makeRequest(t, sv)); err != nil {
+		t.Fatal(err)
+	}
+	events := test.DrainEvents(r.recorder.(*record.FakeRecorder))
+	if (len(events) != 0 || len(expected) != 0) && !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Expected Events\n%q\nbut got\n%q", expected, events)
+	}
+}
+
+// TestEvents walks a SharedVolume through its lifecycle, checking the Events along the way.
+func TestEvents(t *testing.T) {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	r := fakeReconciler()
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID: "fsap-abc123abc123",
+			FileSystemID:  "fs-123abc",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+
+	checkEvents(t, r, sv, "Normal FinalizerRegistered Registered finalizer "+svFinalizer)
+	checkEvents(t, r, sv, "Normal Pending SharedVolume is Pending")
+	checkEvents(t, r, sv,
+		"Normal Created Created PersistentVolume pv-proj1-sv-ee8069eb38",
+		"Normal Created Created PersistentVolumeClaim pvc-sv",
+		"Normal Ready SharedVolume is Ready")
+	// Steady state is quiet
+	checkEvents(t, r, sv)
+
+	// Somebody edits the spec behind the webhook's back
+	svMap, _, _ := getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	sv.Spec.AccessPointID = "fsap-feedfacefeedface"
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	revertsBefore := testutil.ToFloat64(metrics.SpecReverts.WithLabelValues("proj1"))
+	checkEvents(t, r, sv, "Warning SpecReverted Reverted changes to the spec. If you need to attach to a "+
+		"different file system or access point, delete the SharedVolume and create a new one.")
+	if reverts := testutil.ToFloat64(metrics.SpecReverts.WithLabelValues("proj1")) - revertsBefore; reverts != 1 {
+		t.Fatalf("Expected 1 spec revert to be counted but got %v", reverts)
+	}
+	checkEvents(t, r, sv)
+
+	// Mark it for deletion, as kubernetes would
+	svMap, _, _ = getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	delTime := metav1.Now()
+	sv.DeletionTimestamp = &delTime
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	checkEvents(t, r, sv,
+		"Normal Deleting SharedVolume is Deleting",
+		"Normal Deleted Deleted PersistentVolumeClaim pvc-sv",
+		"Normal Deleted Deleted PersistentVolume pv-proj1-sv-ee8069eb38")
+}
+
+// TestFailedEvents checks that a failure recorded in the Status also shows up as a Warning Event.
+func TestFailedEvents(t *testing.T) {
+	r := fakeReconciler()
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sv",
+			Namespace:  "proj1",
+			Finalizers: []string{svFinalizer},
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			FileSystemID: "fs-123abc",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	checkEvents(t, r, sv,
+		"Warning Failed spec.accessPointID: Required value: one of accessPointID, accessPoint or source must be specified")
+	// Not again for the same failure
+	checkEvents(t, r, sv)
+}
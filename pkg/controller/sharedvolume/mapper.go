@@ -3,10 +3,14 @@ package sharedvolume
 // Helpers for mapping secondary resources back to the SharedVolume that owns them.
 
 import (
+	"context"
+
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -16,12 +20,12 @@ const (
 	svOwnerNameKey      = "openshift.io/aws-efs-operator-shared-volume-owner-name"
 )
 
-func toSharedVolume(mo handler.MapObject) []reconcile.Request {
-	labels := mo.Meta.GetLabels()
+func toSharedVolume(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
 	svNamespace := labels[svOwnerNamespaceKey]
 	svName := labels[svOwnerNameKey]
 	if svNamespace == "" || svName == "" {
-		log.Info("Object not owned by any SharedVolume. This is unexpected.", "object", mo.Object)
+		log.Info("Object not owned by any SharedVolume. This is unexpected.", "object", obj)
 		// But what can we do about it?
 		return []reconcile.Request{}
 	}
@@ -36,6 +40,121 @@ func toSharedVolume(mo handler.MapObject) []reconcile.Request {
 	}
 }
 
+// podToSharedVolumes returns a mapper from a Pod to the SharedVolumes in its namespace, any of
+// which it might be using. (We only watch the Pods' metadata, which doesn't say.)
+func podToSharedVolumes(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		return listSharedVolumes(c, obj, client.InNamespace(obj.GetNamespace()))
+	}
+}
+
+// sourceToSharedVolumes returns a mapper from a SharedVolumeSource to the SharedVolumes whose
+// Spec.Source refers to it.
+func sourceToSharedVolumes(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		svList := &awsefsv1alpha1.SharedVolumeList{}
+		if err := c.List(context.TODO(), svList); err != nil {
+			log.Error(err, "Failed to list SharedVolumes", "SharedVolumeSource", obj.GetName())
+			return []reconcile.Request{}
+		}
+		requests := []reconcile.Request{}
+		for _, sv := range svList.Items {
+			if sv.Spec.Source != obj.GetName() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: sv.Namespace,
+					Name:      sv.Name,
+				},
+			})
+		}
+		return requests
+	}
+}
+
+// storageClassToSharedVolumes returns a mapper from a StorageClass to the SharedVolumes whose
+// Spec.StorageClassName refers to it.
+func storageClassToSharedVolumes(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		svList := &awsefsv1alpha1.SharedVolumeList{}
+		if err := c.List(context.TODO(), svList); err != nil {
+			log.Error(err, "Failed to list SharedVolumes", "StorageClass", obj.GetName())
+			return []reconcile.Request{}
+		}
+		requests := []reconcile.Request{}
+		for _, sv := range svList.Items {
+			if sv.Spec.StorageClassName != obj.GetName() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: sv.Namespace,
+					Name:      sv.Name,
+				},
+			})
+		}
+		return requests
+	}
+}
+
+// policyToSharedVolumes returns a mapper from a SharedVolumePolicy to the SharedVolumes in the
+// namespaces it applies to. (Edits are mapped both before and after, so namespaces the policy
+// stops applying to are covered too.) If its namespaceSelector is invalid, which makes the policy
+// fail every SharedVolume, it maps to all of them.
+func policyToSharedVolumes(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		p, ok := obj.(*awsefsv1alpha1.SharedVolumePolicy)
+		if !ok {
+			return []reconcile.Request{}
+		}
+		nsList := &corev1.NamespaceList{}
+		if err := c.List(context.TODO(), nsList); err != nil {
+			log.Error(err, "Failed to list Namespaces", "SharedVolumePolicy", p.Name)
+			return []reconcile.Request{}
+		}
+		requests := []reconcile.Request{}
+		for i := range nsList.Items {
+			ns := &nsList.Items[i]
+			applies, err := p.AppliesTo(ns)
+			if err != nil {
+				return listSharedVolumes(c, obj)
+			}
+			if applies {
+				requests = append(requests, listSharedVolumes(c, obj, client.InNamespace(ns.Name))...)
+			}
+		}
+		return requests
+	}
+}
+
+// namespaceToSharedVolumes returns a mapper from a Namespace to the SharedVolumes in it.
+func namespaceToSharedVolumes(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		return listSharedVolumes(c, obj, client.InNamespace(obj.GetName()))
+	}
+}
+
+// listSharedVolumes returns requests for all the SharedVolumes matching the `opts`, on behalf of
+// the mapper for `obj`.
+func listSharedVolumes(c client.Client, obj client.Object, opts ...client.ListOption) []reconcile.Request {
+	svList := &awsefsv1alpha1.SharedVolumeList{}
+	if err := c.List(context.TODO(), svList, opts...); err != nil {
+		log.Error(err, "Failed to list SharedVolumes", "trigger", obj.GetName())
+		return []reconcile.Request{}
+	}
+	requests := make([]reconcile.Request, 0, len(svList.Items))
+	for _, sv := range svList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: sv.Namespace,
+				Name:      sv.Name,
+			},
+		})
+	}
+	return requests
+}
+
 func setSharedVolumeOwner(owned metav1.Object, owner *awsefsv1alpha1.SharedVolume) {
 	// Note: Owner References would theoretically be a better fit here, but they're heavier than
 	// what we need, and the existing utilities (controller-runtime/pkg/controller/controllerutil)
@@ -47,3 +166,10 @@ func setSharedVolumeOwner(owned metav1.Object, owner *awsefsv1alpha1.SharedVolum
 	labels[svOwnerNamespaceKey] = owner.Namespace
 	labels[svOwnerNameKey] = owner.Name
 }
+
+// ownedBy returns whether the `owned` resource is labeled (by setSharedVolumeOwner) as belonging
+// to the `owner` SharedVolume.
+func ownedBy(owned metav1.Object, owner *awsefsv1alpha1.SharedVolume) bool {
+	labels := owned.GetLabels()
+	return labels[svOwnerNamespaceKey] == owner.Namespace && labels[svOwnerNameKey] == owner.Name
+}
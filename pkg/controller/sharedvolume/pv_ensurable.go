@@ -5,23 +5,28 @@ package sharedvolume
 import (
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
 	"openshift/aws-efs-operator/pkg/controller/statics"
+	"openshift/aws-efs-operator/pkg/metrics"
 	util "openshift/aws-efs-operator/pkg/util"
 
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Cache of PV Ensurables by SharedVolume namespace and name
-var pvBySharedVolume = make(map[string]util.Ensurable)
+var pvBySharedVolume = newEnsurableCache()
 
 func pvEnsurable(sharedVolume *awsefsv1alpha1.SharedVolume) util.Ensurable {
-	key := svKey(sharedVolume)
-	if _, ok := pvBySharedVolume[key]; !ok {
-		pvBySharedVolume[key] = &util.EnsurableImpl{
+	return pvBySharedVolume.getOrCreate(svKey(sharedVolume), func() util.Ensurable {
+		return &util.EnsurableImpl{
 			ObjType:        &corev1.PersistentVolume{},
+			OnChange:       metrics.CountEnsureActions("PersistentVolume"),
 			NamespacedName: pvNamespacedName(sharedVolume),
 			Definition:     pvDefinition(sharedVolume),
 			// NOTE: PVs are immutable once created, so theoretically we should never encounter an
@@ -33,8 +38,7 @@ func pvEnsurable(sharedVolume *awsefsv1alpha1.SharedVolume) util.Ensurable {
 			// [1] https://github.com/openshift/aws-efs-operator/pull/17/commits/bfcfcda1158510a28cc253a76c74fd03edd20a4f#diff-b7b6189fad2ed163b0a2ff5f7f22ad50L73-L81
 			EqualFunc: util.AlwaysEqual,
 		}
-	}
-	return pvBySharedVolume[key]
+	})
 }
 
 func pvNamespacedName(sharedVol *awsefsv1alpha1.SharedVolume) types.NamespacedName {
@@ -44,17 +48,114 @@ func pvNamespacedName(sharedVol *awsefsv1alpha1.SharedVolume) types.NamespacedNa
 	}
 }
 
+// pvNameHashLength is the number of hex digits of the hash suffixed to PV names by newPVName.
+const pvNameHashLength = 10
+
+// pvNameForSharedVolume returns the name of the `sharedVolume`'s PV: the one recorded in its
+// Status (see discoverPVName), or else the one newPVName would give it.
 func pvNameForSharedVolume(sharedVolume *awsefsv1alpha1.SharedVolume) string {
-	// Name the PV after the SharedVolume so it's easy to spot visually.
+	if sharedVolume.Status.VolumeName != "" {
+		return sharedVolume.Status.VolumeName
+	}
+	return newPVName(sharedVolume)
+}
+
+// newPVName returns the name to give a new PV for the `sharedVolume`. Name the PV after the
+// SharedVolume so it's easy to spot visually. The suffix, a hash of the namespace and name, keeps
+// it unique -- otherwise namespace `a-b` with name `c` would collide with namespace `a` with name
+// `b-c` -- including when the readable part has to be truncated to fit the length limit.
+func newPVName(sharedVolume *awsefsv1alpha1.SharedVolume) string {
+	// Neither namespaces nor names can contain a slash, so this is unambiguous.
+	sum := sha256.Sum256([]byte(sharedVolume.Namespace + "/" + sharedVolume.Name))
+	suffix := "-" + hex.EncodeToString(sum[:])[:pvNameHashLength]
+	prefix := legacyPVName(sharedVolume)
+	if max := validation.DNS1123SubdomainMaxLength - len(suffix); len(prefix) > max {
+		prefix = prefix[:max]
+	}
+	// The character before the suffix's dash must be alphanumeric.
+	return strings.TrimRight(prefix, "-.") + suffix
+}
+
+// legacyPVName returns the name older versions of the operator gave the `sharedVolume`'s PV.
+func legacyPVName(sharedVolume *awsefsv1alpha1.SharedVolume) string {
 	return fmt.Sprintf("pv-%s-%s", sharedVolume.Namespace, sharedVolume.Name)
 }
 
+// fileSystemID returns the ID of the file system backing the `sharedVolume`: either the one
+// specified by the user, or the one taken from its SharedVolumeSource.
+func fileSystemID(sharedVolume *awsefsv1alpha1.SharedVolume) string {
+	if sharedVolume.Spec.FileSystemID != "" {
+		return sharedVolume.Spec.FileSystemID
+	}
+	return sharedVolume.Status.FileSystemID
+}
+
+// accessPointID returns the ID of the access point backing the `sharedVolume`: either the one
+// specified by the user, or the one we provisioned or took from its SharedVolumeSource.
+func accessPointID(sharedVolume *awsefsv1alpha1.SharedVolume) string {
+	if sharedVolume.Spec.AccessPointID != "" {
+		return sharedVolume.Spec.AccessPointID
+	}
+	return sharedVolume.Status.AccessPointID
+}
+
+// storageClassName returns the StorageClass of the `sharedVolume`'s PV and PVC: the one requested
+// in the Spec, if any; otherwise the operator's default.
+func storageClassName(sharedVolume *awsefsv1alpha1.SharedVolume) string {
+	if sharedVolume.Spec.StorageClassName != "" {
+		return sharedVolume.Spec.StorageClassName
+	}
+	return statics.StorageClassName
+}
+
+// accessModes returns the access modes for the `sharedVolume`'s PV and PVC.
+func accessModes(sharedVolume *awsefsv1alpha1.SharedVolume) []corev1.PersistentVolumeAccessMode {
+	if sharedVolume.Spec.ReadOnly {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}
+	}
+	return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+}
+
+// Mount options understood by the EFS mount helper
+const (
+	mountOptionTLS = "tls"
+	mountOptionIAM = "iam"
+)
+
+// mountOptions returns the mount options for the `sharedVolume`'s PV, or nil if there are none.
+func mountOptions(sharedVolume *awsefsv1alpha1.SharedVolume) []string {
+	var opts []string
+	if sharedVolume.Spec.EncryptInTransit {
+		opts = append(opts, mountOptionTLS)
+	}
+	if sharedVolume.Spec.IAMAuthorization {
+		opts = append(opts, mountOptionIAM)
+	}
+	return opts
+}
+
+// parseMountOptions is the reverse of mountOptions: it returns the values of the SharedVolume's
+// EncryptInTransit and IAMAuthorization implied by the `pv`'s mount options.
+func parseMountOptions(pv *corev1.PersistentVolume) (encryptInTransit, iamAuthorization bool) {
+	for _, opt := range pv.Spec.MountOptions {
+		switch opt {
+		case mountOptionTLS:
+			encryptInTransit = true
+		case mountOptionIAM:
+			iamAuthorization = true
+		}
+	}
+	return
+}
+
 func pvDefinition(sharedVolume *awsefsv1alpha1.SharedVolume) *corev1.PersistentVolume {
 	filesystem := corev1.PersistentVolumeFilesystem
-	volumeHandle := fmt.Sprintf("%s::%s", sharedVolume.Spec.FileSystemID, sharedVolume.Spec.AccessPointID)
+	volumeHandle := fmt.Sprintf("%s::%s", fileSystemID(sharedVolume), accessPointID(sharedVolume))
 	pv := &corev1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: pvNameForSharedVolume(sharedVolume),
+			Name:        pvNameForSharedVolume(sharedVolume),
+			Labels:      copyMap(sharedVolume.Spec.ClaimLabels),
+			Annotations: copyMap(sharedVolume.Spec.ClaimAnnotations),
 		},
 		Spec: corev1.PersistentVolumeSpec{
 			Capacity: corev1.ResourceList{
@@ -63,13 +164,15 @@ func pvDefinition(sharedVolume *awsefsv1alpha1.SharedVolume) *corev1.PersistentV
 				corev1.ResourceStorage: efsSize,
 			},
 			VolumeMode:                    &filesystem,
-			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			AccessModes:                   accessModes(sharedVolume),
 			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
-			StorageClassName:              statics.StorageClassName,
+			MountOptions:                  mountOptions(sharedVolume),
+			StorageClassName:              storageClassName(sharedVolume),
 			PersistentVolumeSource: corev1.PersistentVolumeSource{
 				CSI: &corev1.CSIPersistentVolumeSource{
 					Driver:       statics.CSIDriverName,
 					VolumeHandle: volumeHandle,
+					ReadOnly:     sharedVolume.Spec.ReadOnly,
 				},
 			},
 		},
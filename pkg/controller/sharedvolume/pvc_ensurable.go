@@ -4,7 +4,7 @@ package sharedvolume
 
 import (
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
-	"openshift/aws-efs-operator/pkg/controller/statics"
+	"openshift/aws-efs-operator/pkg/metrics"
 	util "openshift/aws-efs-operator/pkg/util"
 
 	"fmt"
@@ -17,13 +17,13 @@ import (
 )
 
 // Cache of PVC Ensurables by SharedVolume namespace and name
-var pvcBySharedVolume = make(map[string]util.Ensurable)
+var pvcBySharedVolume = newEnsurableCache()
 
 func pvcEnsurable(sharedVolume *awsefsv1alpha1.SharedVolume) util.Ensurable {
-	key := svKey(sharedVolume)
-	if _, ok := pvcBySharedVolume[key]; !ok {
-		pvcBySharedVolume[key] = &util.EnsurableImpl{
+	return pvcBySharedVolume.getOrCreate(svKey(sharedVolume), func() util.Ensurable {
+		return &util.EnsurableImpl{
 			ObjType:        &corev1.PersistentVolumeClaim{},
+			OnChange:       metrics.CountEnsureActions("PersistentVolumeClaim"),
 			NamespacedName: pvcNamespacedName(sharedVolume),
 			Definition:     pvcDefinition(sharedVolume),
 			// PVCs are (almost*) immutable once created, so doing an equals check is probably
@@ -37,29 +37,43 @@ func pvcEnsurable(sharedVolume *awsefsv1alpha1.SharedVolume) util.Ensurable {
 					server.(*corev1.PersistentVolumeClaim).Spec)
 			},
 		}
-	}
-	return pvcBySharedVolume[key]
+	})
 }
 
 func pvcNamespacedName(sharedVolume *awsefsv1alpha1.SharedVolume) types.NamespacedName {
 	return types.NamespacedName{
-		// Name the PVC after the SharedVolume so it's easy to spot visually.
-		Name:      fmt.Sprintf("pvc-%s", sharedVolume.Name),
+		Name:      pvcName(sharedVolume),
 		Namespace: sharedVolume.Namespace,
 	}
 }
 
+// pvcName returns the name of the `sharedVolume`'s PVC: the one requested in the Spec, if any;
+// otherwise the default.
+func pvcName(sharedVolume *awsefsv1alpha1.SharedVolume) string {
+	if sharedVolume.Spec.ClaimName != "" {
+		return sharedVolume.Spec.ClaimName
+	}
+	return defaultPVCName(sharedVolume)
+}
+
+func defaultPVCName(sharedVolume *awsefsv1alpha1.SharedVolume) string {
+	// Name the PVC after the SharedVolume so it's easy to spot visually.
+	return fmt.Sprintf("pvc-%s", sharedVolume.Name)
+}
+
 func pvcDefinition(sharedVolume *awsefsv1alpha1.SharedVolume) *corev1.PersistentVolumeClaim {
 	nsname := pvcNamespacedName(sharedVolume)
-	scname := statics.StorageClassName
+	scname := storageClassName(sharedVolume)
 	filesystem := corev1.PersistentVolumeFilesystem
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      nsname.Name,
-			Namespace: nsname.Namespace,
+			Name:        nsname.Name,
+			Namespace:   nsname.Namespace,
+			Labels:      copyMap(sharedVolume.Spec.ClaimLabels),
+			Annotations: copyMap(sharedVolume.Spec.ClaimAnnotations),
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			AccessModes: accessModes(sharedVolume),
 			Resources: corev1.ResourceRequirements{
 				Requests: corev1.ResourceList{
 					// NOTE: This is ignored by the CSI driver, but a value is required to create a PVC.
@@ -0,0 +1,89 @@
+package sharedvolume
+
+// Recovery from out-of-band deletion of an operator-owned PV or PVC
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	util "openshift/aws-efs-operator/pkg/util"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recoveryReason inspects the `pvc` and `pv` (either of which may be nil) and, if one of them has
+// been left unusable by the deletion of the other, returns a description of the problem.
+// Otherwise it returns "". Once a PVC is Lost or a PV is Released, kubernetes will never bind
+// them again, so the only way out is to delete and recreate the pair.
+func recoveryReason(pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) string {
+	if pvc != nil {
+		if pvc.GetDeletionTimestamp() != nil {
+			return fmt.Sprintf("PersistentVolumeClaim %s is being deleted", pvc.Name)
+		}
+		if pvc.Status.Phase == corev1.ClaimLost {
+			return fmt.Sprintf("PersistentVolumeClaim %s is %s", pvc.Name, pvc.Status.Phase)
+		}
+	}
+	if pv != nil {
+		if pv.GetDeletionTimestamp() != nil {
+			return fmt.Sprintf("PersistentVolume %s is being deleted", pv.Name)
+		}
+		if pv.Status.Phase == corev1.VolumeReleased {
+			return fmt.Sprintf("PersistentVolume %s is %s", pv.Name, pv.Status.Phase)
+		}
+		// The PVC was deleted and recreated before the PV controller noticed.
+		if pvc != nil && pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.UID != "" && pv.Spec.ClaimRef.UID != pvc.UID {
+			return fmt.Sprintf("PersistentVolume %s is bound to a previous incarnation of PersistentVolumeClaim %s",
+				pv.Name, pvc.Name)
+		}
+	}
+	return ""
+}
+
+// recoverPair deletes the PVC and PV of the `sharedVolume` (via their Ensurables `pvce` and `pve`)
+// so they can be recreated from scratch, and updates the SharedVolume's Status to report progress.
+// `pvc` and `pv` are the versions we last saw, or nil if they're already gone; and `reason` is
+// from recoveryReason, if any.
+// The caller should requeue until both are gone, and then carry on as if the SharedVolume were new.
+func (r *ReconcileSharedVolume) recoverPair(
+	ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume, pve, pvce util.Ensurable,
+	pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume, reason string) error {
+
+	logger.Info("Recovering PersistentVolume and PersistentVolumeClaim", "reason", reason)
+	// Delete the PVC first. Otherwise the PV controller may bind the surviving PVC to the PV we
+	// recreate, only for the PV to be Released again when the PVC goes away.
+	if err := pvce.Delete(ctx, logger, r.client); err != nil {
+		return err
+	}
+	// Deleting through the Ensurable also drops the cached copy of the PV from the server, so the
+	// PV is recreated from its definition, without the stale claimRef that would otherwise keep
+	// it from binding to the new PVC.
+	if err := pve.Delete(ctx, logger, r.client); err != nil {
+		return err
+	}
+
+	waitingFor := []string{}
+	if pvc != nil {
+		waitingFor = append(waitingFor, fmt.Sprintf("PersistentVolumeClaim %s", pvc.Name))
+	}
+	if pv != nil {
+		waitingFor = append(waitingFor, fmt.Sprintf("PersistentVolume %s", pv.Name))
+	}
+	message := fmt.Sprintf("Waiting for %s to be deleted", strings.Join(waitingFor, " and "))
+	if reason != "" {
+		message = fmt.Sprintf("%s. %s", reason, message)
+	}
+	if pvc != nil {
+		// Deletion is held up by the pvc-protection finalizer as long as pods are using the PVC.
+		message += ". Pods using the PersistentVolumeClaim must be deleted first"
+	}
+	return r.markStatus(ctx, logger, sharedVolume, awsefsv1alpha1.SharedVolumeRecovering, message,
+		newCondition(awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionFalse, reasonRecovering, message),
+		newCondition(awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionFalse, reasonRecovering, message),
+		newCondition(awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonRecovering, message),
+		degraded(reasonRecovering, message))
+}
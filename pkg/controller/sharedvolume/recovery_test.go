@@ -0,0 +1,207 @@
+package sharedvolume
+
+import (
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/test"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRecoveryReason(t *testing.T) {
+	now := metav1.Now()
+	mkPVC := func(phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-sv", Namespace: "proj1", UID: "new-uid"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	mkPV := func(phase corev1.PersistentVolumePhase, claimUID types.UID) *corev1.PersistentVolume {
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-proj1-sv-ee8069eb38"},
+			Status:     corev1.PersistentVolumeStatus{Phase: phase},
+		}
+		if claimUID != "" {
+			pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "proj1", Name: "pvc-sv", UID: claimUID}
+		}
+		return pv
+	}
+	deletingPVC := mkPVC(corev1.ClaimBound)
+	deletingPVC.DeletionTimestamp = &now
+	deletingPV := mkPV(corev1.VolumeBound, "new-uid")
+	deletingPV.DeletionTimestamp = &now
+
+	tests := []struct {
+		name string
+		pvc  *corev1.PersistentVolumeClaim
+		pv   *corev1.PersistentVolume
+		want string
+	}{
+		{"neither exists", nil, nil, ""},
+		{"not cached yet", mkPVC(""), nil, ""},
+		{"pending", mkPVC(corev1.ClaimPending), mkPV(corev1.VolumeAvailable, ""), ""},
+		{"bound", mkPVC(corev1.ClaimBound), mkPV(corev1.VolumeBound, "new-uid"), ""},
+		{"pv failed", mkPVC(corev1.ClaimBound), mkPV(corev1.VolumeFailed, "new-uid"), ""},
+		{"pvc lost", mkPVC(corev1.ClaimLost), nil, "PersistentVolumeClaim pvc-sv is Lost"},
+		{"pvc deleting", deletingPVC, mkPV(corev1.VolumeBound, "new-uid"), "PersistentVolumeClaim pvc-sv is being deleted"},
+		{"pv released", nil, mkPV(corev1.VolumeReleased, "old-uid"), "PersistentVolume pv-proj1-sv-ee8069eb38 is Released"},
+		{"pv deleting", mkPVC(corev1.ClaimBound), deletingPV, "PersistentVolume pv-proj1-sv-ee8069eb38 is being deleted"},
+		{"stale claimRef", mkPVC(corev1.ClaimPending), mkPV(corev1.VolumeBound, "old-uid"),
+			"PersistentVolume pv-proj1-sv-ee8069eb38 is bound to a previous incarnation of PersistentVolumeClaim pvc-sv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recoveryReason(tt.pvc, tt.pv); got != tt.want {
+				t.Fatalf("Expected %q but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// readySharedVolume creates a SharedVolume, after applying any `mutators`, and reconciles it until
+// it's Ready.
+func readySharedVolume(t *testing.T, r *ReconcileSharedVolume, mutators ...func(*awsefsv1alpha1.SharedVolume)) reconcile.Request {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID: "fsap-abc123abc123",
+			FileSystemID:  "fs-123abc",
+		},
+	}
+	for _, mutate := range mutators {
+		mutate(sv)
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+	// Finalizer, Pending, then create the PV and PVC, which our fake client binds right away.
+	for _, expected := range []reconcile.Result{test.RequeueResult, test.RequeueResult, test.NullResult} {
+		if res, err := r.Reconcile(ctx, req); res != expected || err != nil {
+			t.Fatalf("Expected %v, no error; got\nresult: %v\nerr: %v", expected, res, err)
+		}
+	}
+	validateResources(t, r.client, 1)
+	return req
+}
+
+// expectRecovering reconciles and checks that the SharedVolume is Recovering with `message`.
+func expectRecovering(t *testing.T, r *ReconcileSharedVolume, req reconcile.Request, message string) {
+	t.Helper()
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	sv := svMap["proj1/sv"]
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeRecovering || sv.Status.Message != message {
+		t.Fatalf("Expected Recovering phase with message %q but got %s", message, format(sv.Status))
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeBound, metav1.ConditionFalse, reasonRecovering)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reasonRecovering)
+	// Our fake client deletes immediately.
+	if len(pvMap) != 0 || len(pvcMap) != 0 {
+		t.Fatalf("Expected the PV and PVC to be deleted but got\nPVs: %s\nPVCs: %s", pvMap, pvcMap)
+	}
+}
+
+// expectRecovered reconciles and checks that the PV and PVC were recreated.
+func expectRecovered(t *testing.T, r *ReconcileSharedVolume, req reconcile.Request) {
+	t.Helper()
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, _ := validateResources(t, r.client, 1)
+	sv := svMap["proj1/sv"]
+	if sv.Status.Message != "" {
+		t.Fatalf("Expected no message but got %q", sv.Status.Message)
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeBound, metav1.ConditionTrue, reasonClaimBound)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected)
+	if pv := pvMap["/pv-proj1-sv-ee8069eb38"]; pv.Spec.ClaimRef != nil {
+		t.Fatalf("Expected the recreated PV not to have a claimRef but got %s", format(pv.Spec.ClaimRef))
+	}
+}
+
+// TestRecoverDeletedPVC simulates the PVC being deleted out of band, leaving the PV Released.
+func TestRecoverDeletedPVC(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r)
+
+	// Play the part of the PV controller: bind the PV to the PVC; and reconcile so the operator
+	// caches the PV with its claimRef.
+	_, pvMap, pvcMap := getResources(t, r.client)
+	pv := pvMap["/pv-proj1-sv-ee8069eb38"]
+	pvc := pvcMap["proj1/pvc-sv"]
+	pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name, UID: pvc.UID}
+	if err := r.client.Update(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+
+	// Now delete the PVC, which Releases the PV.
+	if err := r.client.Delete(ctx, pvc); err != nil {
+		t.Fatal(err)
+	}
+	pv.Status.Phase = corev1.VolumeReleased
+	if err := r.client.Update(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRecovering(t, r, req,
+		"PersistentVolume pv-proj1-sv-ee8069eb38 is Released. Waiting for PersistentVolume pv-proj1-sv-ee8069eb38 to be deleted")
+	expectRecovered(t, r, req)
+}
+
+// TestRecoverDeletedPV simulates the PV being deleted out of band, leaving the PVC Lost.
+func TestRecoverDeletedPV(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r)
+
+	_, pvMap, pvcMap := getResources(t, r.client)
+	if err := r.client.Delete(ctx, pvMap["/pv-proj1-sv-ee8069eb38"]); err != nil {
+		t.Fatal(err)
+	}
+	pvc := pvcMap["proj1/pvc-sv"]
+	pvc.Status.Phase = corev1.ClaimLost
+	if err := r.client.Update(ctx, pvc); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRecovering(t, r, req,
+		"PersistentVolumeClaim pvc-sv is Lost. Waiting for PersistentVolumeClaim pvc-sv to be deleted. "+
+			"Pods using the PersistentVolumeClaim must be deleted first")
+	expectRecovered(t, r, req)
+}
+
+// TestRecoverStaleClaimRef simulates the PVC being deleted and recreated before the PV controller
+// noticed, leaving the PV bound to the old one.
+func TestRecoverStaleClaimRef(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r)
+
+	_, pvMap, _ := getResources(t, r.client)
+	pv := pvMap["/pv-proj1-sv-ee8069eb38"]
+	pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "proj1", Name: "pvc-sv", UID: "old-uid"}
+	if err := r.client.Update(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRecovering(t, r, req,
+		"PersistentVolume pv-proj1-sv-ee8069eb38 is bound to a previous incarnation of PersistentVolumeClaim pvc-sv. "+
+			"Waiting for PersistentVolumeClaim pvc-sv and PersistentVolume pv-proj1-sv-ee8069eb38 to be deleted. "+
+			"Pods using the PersistentVolumeClaim must be deleted first")
+	expectRecovered(t, r, req)
+}
@@ -3,36 +3,57 @@ package sharedvolume
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/controller/statics"
+	"openshift/aws-efs-operator/pkg/efs"
+	"openshift/aws-efs-operator/pkg/metrics"
+	"openshift/aws-efs-operator/pkg/policy"
 	"openshift/aws-efs-operator/pkg/util"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	// TODO: Is there a lib const for this somewhere?
-	pvcKind     = "PersistentVolumeClaim"
-	svFinalizer = "finalizer.awsefs.managed.openshift.io"
+	pvcKind        = "PersistentVolumeClaim"
+	svFinalizer    = "finalizer.awsefs.managed.openshift.io"
+	controllerName = "sharedvolume-controller"
 )
 
 var log = logf.Log.WithName("controller_sharedvolume")
 
+// MaxConcurrentReconciles is the number of SharedVolumes reconciled in parallel. It must be set
+// before Add is called.
+var MaxConcurrentReconciles = 1
+
 // Add creates a new SharedVolume Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -41,46 +62,92 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileSharedVolume{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileSharedVolume{
+		client:    mgr.GetClient(),
+		apiReader: mgr.GetAPIReader(),
+		scheme:    mgr.GetScheme(),
+		recorder:  mgr.GetEventRecorderFor(controllerName),
+		newEFSClient: func(ctx context.Context) (efs.Client, error) {
+			// Use the API reader so we don't set up a watch on Infrastructures for a one-off lookup.
+			return efs.NewClientForCluster(ctx, mgr.GetAPIReader())
+		},
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
-	// Create a new controller
-	c, err := controller.New("sharedvolume-controller", mgr, controller.Options{Reconciler: r})
-	if err != nil {
-		return err
-	}
-
-	// Watch for changes to primary resource SharedVolume.
-	// (No need for the ICarePredicate here; we want to watch all SharedVolume instances.)
-	err = c.Watch(&source.Kind{Type: &awsefsv1alpha1.SharedVolume{}}, &handler.EnqueueRequestForObject{})
-	if err != nil {
-		return err
-	}
-
-	// Watch PVs that trigger our predicate, and map them to the SharedVolume that owns them.
-	// Note that we can't use owner references because PVs aren't namespaced.
-	err = c.Watch(
-		&source.Kind{Type: &corev1.PersistentVolume{}},
-		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(toSharedVolume)},
-		util.ICarePredicate)
-	if err != nil {
-		return err
-	}
-
-	// Watch PVCs that trigger our predicate, and map them to the SharedVolume that owns them.
-	// (Could have done this with owner references, but prefer being consistent with the way
-	// we're handling PersistentVolumes.)
-	err = c.Watch(
-		&source.Kind{Type: &corev1.PersistentVolumeClaim{}},
-		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(toSharedVolume)},
-		util.ICarePredicate)
+	// The PVs and PVCs we create are tied to their SharedVolume by labels (see
+	// setSharedVolumeOwner) rather than owner references, which can't cross namespaces. So they're
+	// watched via mappers, like the other secondary resources, rather than with Owns().
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		WithOptions(controller.Options{MaxConcurrentReconciles: MaxConcurrentReconciles}).
+		// Watch for changes to primary resource SharedVolume.
+		// (No need for the ICarePredicate here; we want to watch all SharedVolume instances.)
+		For(&awsefsv1alpha1.SharedVolume{}).
+		// Watch PVs that trigger our predicate, and map them to the SharedVolume that owns them.
+		// Note that we can't use owner references because PVs aren't namespaced.
+		Watches(
+			&source.Kind{Type: &corev1.PersistentVolume{}},
+			handler.EnqueueRequestsFromMapFunc(toSharedVolume),
+			builder.WithPredicates(util.ICarePredicate)).
+		// Watch PVCs that trigger our predicate, and map them to the SharedVolume that owns them.
+		// (Could have done this with owner references, but prefer being consistent with the way
+		// we're handling PersistentVolumes.)
+		Watches(
+			&source.Kind{Type: &corev1.PersistentVolumeClaim{}},
+			handler.EnqueueRequestsFromMapFunc(toSharedVolume),
+			builder.WithPredicates(util.ICarePredicate)).
+		// Watch Pods so we can keep the InUse condition current. Only their metadata is cached, since
+		// caching every Pod in the cluster for the sake of a condition would be costly on big
+		// clusters. That doesn't say which PVCs a Pod uses, so map it to all the SharedVolumes in its
+		// namespace, which work out InUse by listing the namespace's Pods (see inUseCondition). Only
+		// creation and deletion are passed on; a pod finishing without being deleted (e.g. a Job's)
+		// is noticed the next time its SharedVolume is reconciled.
+		Watches(
+			&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(podToSharedVolumes(mgr.GetClient())),
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+			})).
+		// Watch SharedVolumeSources, and map them to the SharedVolumes referring to them, so those
+		// notice when the source they're waiting for shows up, or their namespace is allowed or
+		// disallowed.
+		Watches(
+			&source.Kind{Type: &awsefsv1alpha1.SharedVolumeSource{}},
+			handler.EnqueueRequestsFromMapFunc(sourceToSharedVolumes(mgr.GetClient()))).
+		// Watch StorageClasses, and map them to the SharedVolumes naming them, so those notice when
+		// the class they're waiting for shows up, or goes away.
+		Watches(
+			&source.Kind{Type: &storagev1.StorageClass{}},
+			handler.EnqueueRequestsFromMapFunc(storageClassToSharedVolumes(mgr.GetClient())),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+			})).
+		// Watch SharedVolumePolicies, and map them to the SharedVolumes in the namespaces they apply
+		// to...
+		Watches(
+			&source.Kind{Type: &awsefsv1alpha1.SharedVolumePolicy{}},
+			handler.EnqueueRequestsFromMapFunc(policyToSharedVolumes(mgr.GetClient()))).
+		// ...and the labels of Namespaces, which policies may select on.
+		Watches(
+			&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(namespaceToSharedVolumes(mgr.GetClient())),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(event.CreateEvent) bool { return false },
+				DeleteFunc: func(event.DeleteEvent) bool { return false },
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return !reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+				},
+			})).
+		Complete(r)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Report the number of SharedVolumes in each phase, so stuck ones can be alerted on.
+	return metrics.RegisterSharedVolumeCollector(mgr.GetClient())
 }
 
 // blank assignment to verify that ReconcileSharedVolume implements reconcile.Reconciler
@@ -91,7 +158,16 @@ type ReconcileSharedVolume struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
-	scheme *runtime.Scheme
+	// apiReader reads straight from the apiserver, for things we don't want to cache (Pods).
+	apiReader client.Reader
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+	// The EFS client is only needed for SharedVolumes asking us to provision an access point, so
+	// it is created lazily (see getEFSClient) via newEFSClient. That way, clusters not using that
+	// feature don't need to give the operator AWS credentials.
+	efsClient      efs.Client
+	newEFSClient   func(context.Context) (efs.Client, error)
+	efsClientMutex sync.Mutex
 }
 
 // Reconcile reads that state of the cluster for a SharedVolume object and makes changes based on the state read
@@ -99,18 +175,22 @@ type ReconcileSharedVolume struct {
 // Note:
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-func (r *ReconcileSharedVolume) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileSharedVolume) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling SharedVolume")
+	defer metrics.ObserveReconcile(controllerName, time.Now())
+	ctx, cancel := util.ReconcileContext(ctx)
+	defer cancel()
 
 	// Fetch the SharedVolume instance
 	sharedVolume := &awsefsv1alpha1.SharedVolume{}
-	if err := r.client.Get(context.TODO(), request.NamespacedName, sharedVolume); err != nil {
+	if err := r.client.Get(ctx, request.NamespacedName, sharedVolume); err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			reqLogger.Info("SharedVolume was deleted out-of-band.")
+			forget(nsnameKey(request.NamespacedName))
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -118,17 +198,32 @@ func (r *ReconcileSharedVolume) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, err
 	}
 
+	// Work out (once) what the PV is called. Everything below relies on it.
+	if sharedVolume.Status.VolumeName == "" {
+		name, err := r.discoverPVName(ctx, sharedVolume)
+		if err != nil {
+			reqLogger.Error(err, "Failed to retrieve PersistentVolume.")
+			return reconcile.Result{}, err
+		}
+		sharedVolume.Status.VolumeName = name
+		// For a new SharedVolume, this gets recorded along with the Pending phase, below. One
+		// created by an older version of the operator needs it recorded now.
+		if sharedVolume.GetDeletionTimestamp() == nil && sharedVolume.Status.Phase != "" {
+			return reconcile.Result{Requeue: true}, r.updateStatus(ctx, reqLogger, sharedVolume)
+		}
+	}
+
 	// Deleting?
 	if sharedVolume.GetDeletionTimestamp() != nil {
-		err := r.markStatus(reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeDeleting, "")
+		err := r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeDeleting, "")
 		if err != nil {
 			reqLogger.Error(err, "Error updating SharedVolume status")
 		}
-		return reconcile.Result{}, r.handleDelete(reqLogger, sharedVolume)
+		return reconcile.Result{}, r.handleDelete(ctx, reqLogger, sharedVolume)
 	}
 
 	// Try to detect whether the SharedVolume got updated bogusly, and revert it.
-	if updated, err := r.uneditSharedVolume(reqLogger, sharedVolume); err != nil {
+	if updated, err := r.uneditSharedVolume(ctx, reqLogger, sharedVolume); err != nil {
 		// If that didn't work, we really don't want to try to reconcile the PV/PVC.
 		// uneditSharedVolume() logs
 		return reconcile.Result{}, err
@@ -144,7 +239,7 @@ func (r *ReconcileSharedVolume) Reconcile(request reconcile.Request) (reconcile.
 	/////
 
 	// Make sure our finalizer is registered before we start doing things that will need it
-	if updated, err := r.ensureFinalizer(reqLogger, sharedVolume); err != nil {
+	if updated, err := r.ensureFinalizer(ctx, reqLogger, sharedVolume); err != nil {
 		// If that didn't work, don't continue; requeue and let the next iteration try to fix things.
 		return reconcile.Result{}, err
 	} else if updated {
@@ -154,65 +249,238 @@ func (r *ReconcileSharedVolume) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// The validating webhook should have rejected an inconsistent Spec, but it may not be in play.
+	if errs := sharedVolume.Spec.Validate(field.NewPath("spec")); len(errs) != 0 {
+		err := errs.ToAggregate()
+		reqLogger.Error(err, "Invalid SharedVolume")
+		// Don't requeue: the Spec is immutable, so it's not going to get any better.
+		return reconcile.Result{}, r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, err.Error(),
+			degraded(reasonInvalidSpec, err.Error()))
+	}
+
 	// If we never set the status, it means this SharedVolume is new, and we'll be creating the
 	// associated resources.
 	if sharedVolume.Status.Phase == "" {
-		err := r.markStatus(reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumePending, "")
+		err := r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumePending, "", pendingConditions()...)
 		// Whether this worked or not (err could be nil), requeue and let the next Reconcile do the rest.
 		return reconcile.Result{Requeue: true}, err
 	}
+
+	// If the file system and access point come from a SharedVolumeSource, we need to know what
+	// they are before we can build the PV, and the namespace has to be allowed to use them.
+	if sharedVolume.Spec.Source != "" {
+		updated, reason, message, err := r.resolveSource(ctx, reqLogger, sharedVolume)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if reason != "" {
+			reqLogger.Info("Can't use SharedVolumeSource", "reason", reason)
+			// Don't requeue: the watch on SharedVolumeSources brings us back if it changes.
+			// Anything we already created is left alone, so pods already using it aren't disrupted.
+			return reconcile.Result{}, r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, message,
+				degraded(reason, message))
+		}
+		if updated {
+			// Recording the IDs in the Status triggers another reconcile. Let that one do the rest.
+			return reconcile.Result{Requeue: true}, nil
+		}
+	}
+
+	// Cluster administrators may restrict which file systems and access points can be used in this
+	// namespace. As with the SharedVolumeSource allow-list, this is checked every time, and anything
+	// we already created is left alone if the SharedVolume is found in violation.
+	apid := accessPointID(sharedVolume)
+	if sharedVolume.Spec.AccessPoint != nil {
+		// Judge it as a request to provision an access point, even once we've done so.
+		apid = ""
+	}
+	if message, err := policy.Check(ctx, r.client, sharedVolume.Namespace, fileSystemID(sharedVolume), apid); err != nil {
+		reqLogger.Error(err, "Failed to check SharedVolumePolicies")
+		return reconcile.Result{}, err
+	} else if message != "" {
+		reqLogger.Info("SharedVolume violates policy", "message", message)
+		// Don't requeue: the watches on SharedVolumePolicies and Namespaces bring us back if they change.
+		return reconcile.Result{}, r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, message,
+			degraded(reasonPolicyViolation, message))
+	}
+
+	// A StorageClass other than the default has to be one of the CSI driver's. Like the checks
+	// above, this is done every time, but leaves anything we already created alone.
+	if reason, message, err := r.checkStorageClass(ctx, reqLogger, sharedVolume); err != nil {
+		return reconcile.Result{}, err
+	} else if reason != "" {
+		reqLogger.Info("Can't use StorageClass", "reason", reason)
+		// Don't requeue: the watch on StorageClasses brings us back if it changes.
+		return reconcile.Result{}, r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, message,
+			degraded(reason, message))
+	}
+
+	// If we're responsible for the access point, it has to exist before we can build the PV
+	// around it.
+	if sharedVolume.Spec.AccessPoint != nil && sharedVolume.Status.AccessPointID == "" {
+		if err := r.provisionAccessPoint(ctx, reqLogger, sharedVolume); err != nil {
+			// Best-effort, as below.
+			_ = r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, err.Error(),
+				degraded(reasonProvisionFailed, err.Error()))
+			return reconcile.Result{}, err
+		}
+		// Recording the access point ID in the Status triggers another reconcile. Let that one do
+		// the rest.
+		return reconcile.Result{Requeue: true}, nil
+	}
 	// Otherwise, don't try to maintain any kind of in-flight status while we check and reconcile
 	// the PV/PVC. Whatever state was set before is fine until we have something new to report.
 	// TODO: Unless it was "Deleting". Could that even happen?
 
-	// TODO: If either the PV or PVC gets munged, the other ends up in a bad/unusable state.
-	//       We probably just want to delete and recreate both
-
 	// The sub-resources we're going to be managing
 	pve := pvEnsurable(sharedVolume)
 	pvce := pvcEnsurable(sharedVolume)
+	// Record Events about them against the SharedVolume, where the app team will see them.
+	pve.SetEventRecorder(r.recorder, sharedVolume)
+	pvce.SetEventRecorder(r.recorder, sharedVolume)
+
+	// If either the PV or PVC got deleted out of band, the other ends up in an unusable state, so
+	// delete what's left and start over. Keep at it until both are gone.
+	pvc, pv, err := r.getPair(ctx, pve, pvce)
+	if err != nil {
+		reqLogger.Error(err, "Failed to retrieve PersistentVolume/PersistentVolumeClaim.")
+		return reconcile.Result{}, err
+	}
+	// Don't touch a PV that isn't ours. That can only happen with a PV created by an older version
+	// of the operator, whose naming scheme allowed collisions. Keep checking, in case it goes away.
+	if pv != nil && !ownedBy(pv, sharedVolume) {
+		message := fmt.Sprintf("PersistentVolume %s already exists and does not belong to this SharedVolume", pv.Name)
+		reqLogger.Info("Volume name conflict", "PersistentVolume", pv.Name)
+		return reconcile.Result{Requeue: true}, r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, message,
+			newCondition(awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionFalse, reasonVolumeNameConflict, message),
+			degraded(reasonVolumeNameConflict, message))
+	}
+	// Don't touch a PVC that isn't ours, e.g. if the user asked for a ClaimName that was already
+	// taken. Keep checking, in case they delete it.
+	if pvc != nil && !ownedBy(pvc, sharedVolume) {
+		message := fmt.Sprintf("PersistentVolumeClaim %s already exists and does not belong to this SharedVolume", pvc.Name)
+		reqLogger.Info("Claim name conflict", "PersistentVolumeClaim", pvc.Name)
+		return reconcile.Result{Requeue: true}, r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, message,
+			newCondition(awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionFalse, reasonClaimNameConflict, message),
+			degraded(reasonClaimNameConflict, message))
+	}
+	reason := recoveryReason(pvc, pv)
+	if reason != "" || (sharedVolume.Status.Phase == awsefsv1alpha1.SharedVolumeRecovering && (pvc != nil || pv != nil)) {
+		return reconcile.Result{Requeue: true}, r.recoverPair(ctx, reqLogger, sharedVolume, pve, pvce, pvc, pv, reason)
+	}
 
 	reqLogger.Info("Reconciling PersistentVolume", "Name", pve.GetNamespacedName().Name)
-	if err := pve.Ensure(reqLogger, r.client); err != nil {
+	if err := pve.Ensure(ctx, reqLogger, r.client); err != nil {
 		// Mark Error status. This is best-effort (ignore any errors), since it's happening within
 		// an error path whose behavior we don't want to disrupt.
 		// Note that we don't clear Status.ClaimRef: if it's set, it might help track
 		// down the cause of the error.
-		_ = r.markStatus(reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, err.Error())
+		_ = r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, err.Error(),
+			newCondition(awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionFalse, reasonEnsureFailed, err.Error()),
+			degraded(reasonEnsureFailed, err.Error()))
 		return reconcile.Result{}, err
 	}
 
 	pvcnsname := pvce.GetNamespacedName()
 	reqLogger.Info("Reconciling PersistentVolumeClaim", "NamespacedName", pvcnsname)
-	if err := pvce.Ensure(reqLogger, r.client); err != nil {
+	if err := pvce.Ensure(ctx, reqLogger, r.client); err != nil {
 		// Mark Error status. This is best-effort (ignore any errors), since it's happening within
 		// an error path whose behavior we don't want to disrupt.
 		// Note that we don't clear Status.ClaimRef: if it's set, it might help track
 		// down the cause of the error.
-		_ = r.markStatus(reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, err.Error())
+		_ = r.markStatus(ctx, reqLogger, sharedVolume, awsefsv1alpha1.SharedVolumeFailed, err.Error(),
+			newCondition(awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionTrue, reasonCreated, ""),
+			newCondition(awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionFalse, reasonEnsureFailed, err.Error()),
+			degraded(reasonEnsureFailed, err.Error()))
+		return reconcile.Result{}, err
+	}
+
+	// If we got this far, the PV/PVC exist (as far as we can tell). Find out whether they're bound
+	// to each other, and whether they're being used.
+	if pvc, pv, err = r.getPair(ctx, pve, pvce); err != nil {
+		reqLogger.Error(err, "Failed to retrieve PersistentVolume/PersistentVolumeClaim.")
 		return reconcile.Result{}, err
 	}
+	inUse, err := inUseCondition(ctx, r.apiReader, pvcnsname)
+	if err != nil {
+		reqLogger.Error(err, "Failed to list pods.", "namespace", pvcnsname.Namespace)
+		return reconcile.Result{}, err
+	}
+
+	phase, err := r.markCreated(ctx, reqLogger, sharedVolume, pvcnsname, pvc, pv, inUse)
+	if err != nil || phase == awsefsv1alpha1.SharedVolumeReady {
+		return reconcile.Result{}, err
+	}
+	// Requeue until the PVC is bound. The watches on the PV and PVC should tell us when that
+	// happens, but this way we don't depend on it. Requeue (as opposed to RequeueAfter) gets us
+	// the controller's rate limiter, i.e. exponential backoff.
+	reqLogger.Info("Waiting for PersistentVolumeClaim to be bound", "phase", phase)
+	return reconcile.Result{Requeue: true}, nil
+}
+
+// getEFSClient returns the EFS client, creating it on first use.
+func (r *ReconcileSharedVolume) getEFSClient(ctx context.Context) (efs.Client, error) {
+	r.efsClientMutex.Lock()
+	defer r.efsClientMutex.Unlock()
+	if r.efsClient == nil {
+		c, err := r.newEFSClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.efsClient = c
+	}
+	return r.efsClient, nil
+}
 
-	// If we got this far, the PV/PVC are good (as far as we can tell).
-	return reconcile.Result{}, r.markReady(reqLogger, sharedVolume, pvcnsname)
+// provisionAccessPoint creates the access point described by the `sharedVolume`'s
+// Spec.AccessPoint and records its ID in the Status.
+func (r *ReconcileSharedVolume) provisionAccessPoint(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) error {
+	efsClient, err := r.getEFSClient(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to create EFS client")
+		return err
+	}
+	logger.Info("Provisioning access point", "FileSystemID", sharedVolume.Spec.FileSystemID)
+	apid, err := efsClient.CreateAccessPoint(ctx, efs.AccessPointRequest{
+		// If we create the access point but fail to record its ID in the Status, the next attempt
+		// will come back with the same one rather than leaking it.
+		ClientToken:  string(sharedVolume.UID),
+		FileSystemID: sharedVolume.Spec.FileSystemID,
+		Spec:         *sharedVolume.Spec.AccessPoint,
+		Tags: map[string]string{
+			svOwnerNamespaceKey: sharedVolume.Namespace,
+			svOwnerNameKey:      sharedVolume.Name,
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to provision access point")
+		return err
+	}
+	logger.Info("Provisioned access point", "AccessPointID", apid)
+	r.recorder.Eventf(sharedVolume, corev1.EventTypeNormal, eventReasonAccessPointCreated,
+		"Created access point %s in file system %s", apid, sharedVolume.Spec.FileSystemID)
+	sharedVolume.Status.AccessPointID = apid
+	return r.updateStatus(ctx, logger, sharedVolume)
 }
 
 // ensureFinalizer makes sure the `sharedVolume` has our finalizer registered.
 // The `bool` return indicates whether an update was pushed to the server.
-func (r *ReconcileSharedVolume) ensureFinalizer(logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) (bool, error) {
+func (r *ReconcileSharedVolume) ensureFinalizer(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) (bool, error) {
 	if util.StringInSlice(svFinalizer, sharedVolume.GetFinalizers()) {
 		return false, nil
 	}
 	logger.Info("Registering finalizer")
 	controllerutil.AddFinalizer(sharedVolume, svFinalizer)
-	if err := r.client.Update(context.TODO(), sharedVolume); err != nil {
+	if err := r.client.Update(ctx, sharedVolume); err != nil {
 		logger.Error(err, "Failed to register finalizer")
 		return false, err
 	}
+	r.recorder.Eventf(sharedVolume, corev1.EventTypeNormal, eventReasonFinalizerRegistered,
+		"Registered finalizer %s", svFinalizer)
 	return true, nil
 }
 
-func (r *ReconcileSharedVolume) handleDelete(logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) error {
+func (r *ReconcileSharedVolume) handleDelete(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) error {
 	if !util.StringInSlice(svFinalizer, sharedVolume.GetFinalizers()) {
 		// Nothing to do
 		return nil
@@ -226,38 +494,132 @@ func (r *ReconcileSharedVolume) handleDelete(logger logr.Logger, sharedVolume *a
 	// TODO(efried): Move cache cleaning logic into pv[c]_ensurable.go... somehow.
 	// Order matters here. Delete the PVC first...
 	e := pvcEnsurable(sharedVolume)
+	e.SetEventRecorder(r.recorder, sharedVolume)
 	k := svKey(sharedVolume)
-	defer delete(pvcBySharedVolume, k)
-	if err := e.Delete(logger, r.client); err != nil {
-		// Delete did the logging
+	defer pvcBySharedVolume.remove(k)
+	if owned, err := r.owns(ctx, sharedVolume, e); err != nil {
+		logger.Error(err, "Failed to retrieve PersistentVolumeClaim.")
 		return err
+	} else if owned {
+		if err := e.Delete(ctx, logger, r.client); err != nil {
+			// Delete did the logging
+			return err
+		}
 	}
 	// ...then the PV
 	e = pvEnsurable(sharedVolume)
-	defer delete(pvBySharedVolume, k)
-	if err := e.Delete(logger, r.client); err != nil {
-		// Delete did the logging
+	e.SetEventRecorder(r.recorder, sharedVolume)
+	defer pvBySharedVolume.remove(k)
+	if owned, err := r.owns(ctx, sharedVolume, e); err != nil {
+		logger.Error(err, "Failed to retrieve PersistentVolume.")
+		return err
+	} else if owned {
+		if err := e.Delete(ctx, logger, r.client); err != nil {
+			// Delete did the logging
+			return err
+		}
+	}
+	// ...then the access point, if we provisioned it and were asked to clean it up.
+	if err := r.reclaimAccessPoint(ctx, logger, sharedVolume); err != nil {
 		return err
 	}
 
 	// We're done. Remove our finalizer and let the SharedVolume deletion proceed.
 	controllerutil.RemoveFinalizer(sharedVolume, svFinalizer)
-	if err := r.client.Update(context.TODO(), sharedVolume); err != nil {
+	if err := r.client.Update(ctx, sharedVolume); err != nil {
 		logger.Error(err, "Failed to remove finalizer")
 		return err
 	}
 	return nil
 }
 
+// owns returns whether the resource represented by `e` is owned by the `sharedVolume`. If there's
+// no such resource, that's true, in the sense that there's nobody else's resource in the way.
+func (r *ReconcileSharedVolume) owns(ctx context.Context, sharedVolume *awsefsv1alpha1.SharedVolume, e util.Ensurable) (bool, error) {
+	obj := e.GetType()
+	if err := r.client.Get(ctx, e.GetNamespacedName(), obj); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return ownedBy(obj.(metav1.Object), sharedVolume), nil
+}
+
+// discoverPVName works out the name of the `sharedVolume`'s PV, for recording in its Status. If
+// there's a PV belonging to the SharedVolume with the name older versions of the operator used,
+// that's the one. Otherwise it's a new one, named by newPVName.
+func (r *ReconcileSharedVolume) discoverPVName(ctx context.Context, sharedVolume *awsefsv1alpha1.SharedVolume) (string, error) {
+	name := legacyPVName(sharedVolume)
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		// Too long to have been created
+		return newPVName(sharedVolume), nil
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: name}, pv); err != nil {
+		if errors.IsNotFound(err) {
+			return newPVName(sharedVolume), nil
+		}
+		return "", err
+	}
+	if !ownedBy(pv, sharedVolume) {
+		// The legacy name collided with some other SharedVolume's PV.
+		return newPVName(sharedVolume), nil
+	}
+	return name, nil
+}
+
+// reclaimAccessPoint deletes the access point we provisioned for the `sharedVolume`, if its
+// ReclaimPolicy says so. The PV must be gone first: the PV delete we issued above may still be
+// pending (e.g. the pv-protection finalizer holds it while pods are using it), and we mustn't pull
+// the access point out from under it.
+func (r *ReconcileSharedVolume) reclaimAccessPoint(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) error {
+	apSpec := sharedVolume.Spec.AccessPoint
+	apid := sharedVolume.Status.AccessPointID
+	if apSpec == nil || apSpec.ReclaimPolicy != awsefsv1alpha1.AccessPointDelete || apid == "" {
+		// Nothing to do
+		return nil
+	}
+
+	pvname := pvNamespacedName(sharedVolume)
+	pv := &corev1.PersistentVolume{}
+	if err := r.client.Get(ctx, pvname, pv); err == nil && ownedBy(pv, sharedVolume) {
+		// Returning an error gets us requeued (with backoff) to check again.
+		return fmt.Errorf("waiting for PersistentVolume %s to be deleted before deleting access point %s",
+			pvname.Name, apid)
+	} else if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to retrieve.", "resource", pvname)
+		return err
+	}
+
+	efsClient, err := r.getEFSClient(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to create EFS client")
+		return err
+	}
+	logger.Info("Deleting access point", "AccessPointID", apid)
+	if err := efsClient.DeleteAccessPoint(ctx, apid); err != nil {
+		logger.Error(err, "Failed to delete access point", "AccessPointID", apid)
+		r.recorder.Eventf(sharedVolume, corev1.EventTypeWarning, eventReasonAccessPointDeleteFailed,
+			"Failed to delete access point %s: %v", apid, err)
+		return err
+	}
+	r.recorder.Eventf(sharedVolume, corev1.EventTypeNormal, eventReasonAccessPointDeleted,
+		"Deleted access point %s", apid)
+	return nil
+}
+
 // markStatus tries to update the SharedVolume's Status.Phase if not already `phase`, and the
-// Message likewise, returning any error from the update. Don't use this for the Ready phase -- use
-// markReady instead, because that knows how to handle the PVC bit. Also note that clearing the
-// message is an important part of marking status, so pass in "" if that's what you mean to do.
+// Message likewise, returning any error from the update. Any `conditions` are set as well (see
+// setCondition). Don't use this once the PV and PVC exist -- use markCreated instead, because that
+// knows how to handle the PVC bit. Also note that clearing the message is an important part of marking
+// status, so pass in "" if that's what you mean to do.
 func (r *ReconcileSharedVolume) markStatus(
-	logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume,
-	phase awsefsv1alpha1.SharedVolumePhase, message string) error {
+	ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume,
+	phase awsefsv1alpha1.SharedVolumePhase, message string, conditions ...metav1.Condition) error {
 
-	updateRequired := false
+	updateRequired := setConditions(sharedVolume, conditions...)
+	eventNeeded := phaseEventNeeded(sharedVolume, phase, message)
 	if sharedVolume.Status.Phase != phase {
 		sharedVolume.Status.Phase = phase
 		updateRequired = true
@@ -270,41 +632,93 @@ func (r *ReconcileSharedVolume) markStatus(
 		// No update necessary. Short out.
 		return nil
 	}
-	return r.updateStatus(logger, sharedVolume)
+	if err := r.updateStatus(ctx, logger, sharedVolume); err != nil {
+		return err
+	}
+	if eventNeeded {
+		r.recordPhase(sharedVolume)
+	}
+	return nil
+}
+
+// getPair retrieves the PVC and PV represented by `pvce` and `pve`. Either is returned as nil if
+// it doesn't exist (or hasn't shown up in the cache yet).
+func (r *ReconcileSharedVolume) getPair(ctx context.Context, pve, pvce util.Ensurable) (
+	*corev1.PersistentVolumeClaim, *corev1.PersistentVolume, error) {
+
+	pv := &corev1.PersistentVolume{}
+	if err := r.client.Get(ctx, pve.GetNamespacedName(), pv); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		pv = nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(ctx, pvce.GetNamespacedName(), pvc); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		pvc = nil
+	}
+	return pvc, pv, nil
 }
 
-// markReady tries to update the SharedVolume's Status.ClaimRef per `pvcnsname` and
-// its Phase as Ready, returning an error if the update fails. This only attempts the
-// update if necessary, so as not to trigger an unnecessary Reconcile.
-func (r *ReconcileSharedVolume) markReady(
-	logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume, pvcnsname types.NamespacedName) error {
+// markCreated is for when the PV and PVC have been created. It tries to update the SharedVolume's
+// Status.ClaimRef per `pvcnsname`, and its Phase, Message, ClaimPhase and VolumePhase according to
+// the binding state of the `pvc` and `pv` (either of which may be nil if it isn't in the cache yet).
+// The PVCreated and PVCCreated conditions are set to True, Bound and Degraded according to the
+// binding state, and `inUse` as passed in. It returns the Phase, and an error if the update fails.
+// This only attempts the update if necessary, so as not to trigger an unnecessary Reconcile.
+func (r *ReconcileSharedVolume) markCreated(
+	ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume, pvcnsname types.NamespacedName,
+	pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume,
+	inUse metav1.Condition) (awsefsv1alpha1.SharedVolumePhase, error) {
+
+	phase, message, bound, degradedCond := bindingStatus(pvc, pv)
 
 	// Only update the SharedVolume if necessary. Otherwise this could trigger another reconcile
 	// and get us in a tight loop.
 	// TODO: Better way to construct/populate this TypedLocalObjectReference? Looking for something
 	// like ObjectRefFromObject()
-	updateNeeded := false
-	if sharedVolume.Status.Phase != awsefsv1alpha1.SharedVolumeReady {
-		sharedVolume.Status.Phase = awsefsv1alpha1.SharedVolumeReady
+	updateNeeded := setConditions(sharedVolume,
+		newCondition(awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionTrue, reasonCreated, ""),
+		newCondition(awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionTrue, reasonCreated, ""),
+		bound, inUse, degradedCond)
+	if setBindingPhases(sharedVolume, pvc, pv) {
+		updateNeeded = true
+	}
+	eventNeeded := phaseEventNeeded(sharedVolume, phase, message)
+	if sharedVolume.Status.Phase != phase {
+		sharedVolume.Status.Phase = phase
+		updateNeeded = true
+	}
+	if sharedVolume.Status.Message != message {
+		sharedVolume.Status.Message = message
 		updateNeeded = true
 	}
 	if sharedVolume.Status.ClaimRef.Name != pvcnsname.Name {
 		sharedVolume.Status.ClaimRef.Name = pvcnsname.Name
 		updateNeeded = true
 	}
-	if updateNeeded {
-		return r.updateStatus(logger, sharedVolume)
+	if !updateNeeded {
+		return phase, nil
 	}
-	return nil
+	if err := r.updateStatus(ctx, logger, sharedVolume); err != nil {
+		return phase, err
+	}
+	if eventNeeded {
+		r.recordPhase(sharedVolume)
+	}
+	return phase, nil
 }
 
-func (r *ReconcileSharedVolume) updateStatus(logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) error {
+func (r *ReconcileSharedVolume) updateStatus(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) error {
 	logger.Info("Updating SharedVolume status", "status", sharedVolume.Status)
 	// TODO: I shouldn't have to set this, since PVC is in core.
 	apiGroup := ""
 	sharedVolume.Status.ClaimRef.APIGroup = &apiGroup
 	sharedVolume.Status.ClaimRef.Kind = pvcKind
-	if err := r.client.Status().Update(context.TODO(), sharedVolume); err != nil {
+	if err := r.client.Status().Update(ctx, sharedVolume); err != nil {
 		logger.Error(err, "Failed to update SharedVolume status")
 		return err
 	}
@@ -316,6 +730,8 @@ func (r *ReconcileSharedVolume) updateStatus(logger logr.Logger, sharedVolume *a
 // from what's in the SharedVolume, it means the SharedVolume was "edited", in which case we
 // restore the original values, editing the `sharedVolume` parameter in place and pushing the
 // change back to the server.
+// Edits to the Spec are normally rejected up front by the validating webhook (see
+// pkg/webhook/sharedvolume); this is the fallback for when the webhook isn't in play.
 // The `bool` return indicates whether an update was pushed successfully.
 // NOTE: Cases where we can't glean the FSID/APID are treated in a way that may not be intuitive.
 // This can happen when:
@@ -331,7 +747,7 @@ func (r *ReconcileSharedVolume) updateStatus(logger logr.Logger, sharedVolume *a
 // that case we should either delete the PV/PVC pair and start over, or mark the SharedVolume as
 // Failed and refuse to continue reconciling it, requiring it to be deleted and recreated.
 func (r *ReconcileSharedVolume) uneditSharedVolume(
-	logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) (updated bool, err error) {
+	ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) (updated bool, err error) {
 
 	updated = false
 	err = nil
@@ -341,7 +757,7 @@ func (r *ReconcileSharedVolume) uneditSharedVolume(
 	nsname := types.NamespacedName{
 		Name: pvname,
 	}
-	if err = r.client.Get(context.TODO(), nsname, pv); err != nil {
+	if err = r.client.Get(ctx, nsname, pv); err != nil {
 		if errors.IsNotFound(err) {
 			// We haven't created this PV yet. One way or another, this means we need to trust that
 			// the SharedVolume is copacetic.
@@ -352,6 +768,11 @@ func (r *ReconcileSharedVolume) uneditSharedVolume(
 		logger.Error(err, "Failed to retrieve the associated PV", "PV name", pvname)
 		return
 	}
+	if !ownedBy(pv, sharedVolume) {
+		// Somebody else's PV (see the conflict check in Reconcile). It tells us nothing about what
+		// this SharedVolume should look like.
+		return
+	}
 
 	// Things could get squirrelly here, e.g. if the PV has been changed in ways that leave us
 	// trying to access nil pointers. Safeguard against that.
@@ -399,18 +820,92 @@ func (r *ReconcileSharedVolume) uneditSharedVolume(
 		panic(fmt.Sprintf("Couldn't find Access Point ID in PersistentVolume %s for SharedVolume %s", pvname, svname))
 	}
 
-	// Now make sure the SharedVolume is right
+	// Now make sure the SharedVolume is right.
+	// If the IDs came from a SharedVolumeSource, they're recorded in the Status, not the Spec.
 	updateNeeded := false
-	if sharedVolume.Spec.FileSystemID != fsid {
+	expectFSID := fsid
+	if sharedVolume.Spec.Source != "" {
+		expectFSID = ""
+	}
+	if sharedVolume.Spec.FileSystemID != expectFSID {
 		logger.Info("SharedVolume has an unexpected FileSystemID",
-			"SharedVolume", svname, "Found FSID", sharedVolume.Spec.FileSystemID, "Expected FSID", fsid)
-		sharedVolume.Spec.FileSystemID = fsid
+			"SharedVolume", svname, "Found FSID", sharedVolume.Spec.FileSystemID, "Expected FSID", expectFSID)
+		sharedVolume.Spec.FileSystemID = expectFSID
 		updateNeeded = true
 	}
-	if sharedVolume.Spec.AccessPointID != apid {
+	// Ditto if we provisioned the access point.
+	expectAPID := apid
+	if sharedVolume.Spec.AccessPoint != nil || sharedVolume.Spec.Source != "" {
+		expectAPID = ""
+	}
+	if sharedVolume.Spec.AccessPointID != expectAPID {
 		logger.Info("SharedVolume has an unexpected AccessPointID",
-			"SharedVolume", svname, "Found APID", sharedVolume.Spec.AccessPointID, "Expected APID", apid)
-		sharedVolume.Spec.AccessPointID = apid
+			"SharedVolume", svname, "Found APID", sharedVolume.Spec.AccessPointID, "Expected APID", expectAPID)
+		sharedVolume.Spec.AccessPointID = expectAPID
+		updateNeeded = true
+	}
+	// Ditto the mount options, except for legacy PVs, which always had `tls` (it was needed to use
+	// the access point) regardless of what the SharedVolume said.
+	if len(tokens) != 1 {
+		tls, iam := parseMountOptions(pv)
+		if sharedVolume.Spec.EncryptInTransit != tls || sharedVolume.Spec.IAMAuthorization != iam {
+			logger.Info("SharedVolume has unexpected mount options", "SharedVolume", svname,
+				"Found EncryptInTransit", sharedVolume.Spec.EncryptInTransit, "Expected EncryptInTransit", tls,
+				"Found IAMAuthorization", sharedVolume.Spec.IAMAuthorization, "Expected IAMAuthorization", iam)
+			sharedVolume.Spec.EncryptInTransit = tls
+			sharedVolume.Spec.IAMAuthorization = iam
+			updateNeeded = true
+		}
+	}
+	// Whether the volume is read-only is recorded in the PV's CSI source.
+	if readOnly := pv.Spec.PersistentVolumeSource.CSI.ReadOnly; sharedVolume.Spec.ReadOnly != readOnly {
+		logger.Info("SharedVolume has an unexpected ReadOnly",
+			"SharedVolume", svname, "Found ReadOnly", sharedVolume.Spec.ReadOnly, "Expected ReadOnly", readOnly)
+		sharedVolume.Spec.ReadOnly = readOnly
+		updateNeeded = true
+	}
+	// The StorageClass is recorded in the PV.
+	if scname := pv.Spec.StorageClassName; storageClassName(sharedVolume) != scname {
+		logger.Info("SharedVolume has an unexpected StorageClassName", "SharedVolume", svname,
+			"Found StorageClassName", sharedVolume.Spec.StorageClassName, "Expected StorageClassName", scname)
+		sharedVolume.Spec.StorageClassName = scname
+		if scname == statics.StorageClassName {
+			sharedVolume.Spec.StorageClassName = ""
+		}
+		updateNeeded = true
+	}
+	// The claim name is recorded in the Status once we've created the PVC.
+	if claimName := sharedVolume.Status.ClaimRef.Name; claimName != "" && pvcName(sharedVolume) != claimName {
+		logger.Info("SharedVolume has an unexpected ClaimName",
+			"SharedVolume", svname, "Found ClaimName", sharedVolume.Spec.ClaimName, "Expected ClaimName", claimName)
+		sharedVolume.Spec.ClaimName = claimName
+		if claimName == defaultPVCName(sharedVolume) {
+			sharedVolume.Spec.ClaimName = ""
+		}
+		updateNeeded = true
+	}
+	// The claim labels and annotations are recorded in the PV, which we never update. Leave out our
+	// own labels, and annotations Kubernetes adds -- those can't come from the SharedVolume, or, in
+	// the latter case, are kept as they are.
+	if labels := filterMap(pv.Labels, isOperatorLabel); !reflect.DeepEqual(copyMap(sharedVolume.Spec.ClaimLabels), labels) {
+		logger.Info("SharedVolume has unexpected ClaimLabels",
+			"SharedVolume", svname, "Found ClaimLabels", sharedVolume.Spec.ClaimLabels, "Expected ClaimLabels", labels)
+		sharedVolume.Spec.ClaimLabels = labels
+		updateNeeded = true
+	}
+	if annotations := filterMap(pv.Annotations, isKubernetesAnnotation); !reflect.DeepEqual(
+		filterMap(sharedVolume.Spec.ClaimAnnotations, isKubernetesAnnotation), annotations) {
+		logger.Info("SharedVolume has unexpected ClaimAnnotations", "SharedVolume", svname,
+			"Found ClaimAnnotations", sharedVolume.Spec.ClaimAnnotations, "Expected ClaimAnnotations", annotations)
+		// Any in Kubernetes' domains, which we can't check, stay as they are.
+		restored := filterMap(sharedVolume.Spec.ClaimAnnotations, func(key string) bool { return !isKubernetesAnnotation(key) })
+		if restored == nil {
+			restored = annotations
+		}
+		for k, v := range annotations {
+			restored[k] = v
+		}
+		sharedVolume.Spec.ClaimAnnotations = restored
 		updateNeeded = true
 	}
 	if !updateNeeded {
@@ -421,10 +916,14 @@ func (r *ReconcileSharedVolume) uneditSharedVolume(
 	logger.Info("Detected changes to SharedVolume. Don't do that. "+
 		"If you need to attach to a different file system or access point, "+
 		"delete the SharedVolume and create a new one. Reverting...", "SharedVolume", sharedVolume)
-	if err = r.client.Update(context.TODO(), sharedVolume); err != nil {
+	if err = r.client.Update(ctx, sharedVolume); err != nil {
 		logger.Error(err, "Failed to revert changes to SharedVolume")
 		return
 	}
+	r.recorder.Event(sharedVolume, corev1.EventTypeWarning, eventReasonSpecReverted,
+		"Reverted changes to the spec. If you need to attach to a different file system or access point, "+
+			"delete the SharedVolume and create a new one.")
+	metrics.SpecReverts.WithLabelValues(sharedVolume.Namespace).Inc()
 
 	// That worked. We're done. Tell the caller we pushed an update.
 	updated = true
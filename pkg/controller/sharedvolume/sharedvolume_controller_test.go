@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/efs"
 	"openshift/aws-efs-operator/pkg/fixtures"
 	"openshift/aws-efs-operator/pkg/test"
 	"openshift/aws-efs-operator/pkg/util"
+	"reflect"
 	"runtime/debug"
+	"time"
 
 	"context"
 	"testing"
@@ -18,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	// TODO: pkg/client/fake is deprecated, replace with pkg/envtest
@@ -39,22 +43,69 @@ func fakeReconciler() *ReconcileSharedVolume {
 		awsefsv1alpha1.SchemeGroupVersion,
 		&awsefsv1alpha1.SharedVolume{},
 		&awsefsv1alpha1.SharedVolumeList{},
+		&awsefsv1alpha1.SharedVolumeSource{},
+		&awsefsv1alpha1.SharedVolumeSourceList{},
+		&awsefsv1alpha1.SharedVolumePolicy{},
+		&awsefsv1alpha1.SharedVolumePolicyList{},
 	)
 
+	client := &pvBinder{fake.NewFakeClientWithScheme(sch)}
 	return &ReconcileSharedVolume{
-		client: fake.NewFakeClientWithScheme(sch),
-		scheme: sch,
+		client:    client,
+		apiReader: client,
+		scheme:    sch,
+		recorder:  test.NewFakeRecorder(),
 	}
 }
 
+// pvBinder wraps a (fake) client to play the part of the PV controller, which isn't running in
+// unit tests: PVs and PVCs are Bound as soon as they're written without a phase. Tests that want
+// to see what happens before binding can use the wrapped client directly via unbound().
+type pvBinder struct {
+	crclient.Client
+}
+
+func (b *pvBinder) bind(obj crclient.Object) {
+	switch o := obj.(type) {
+	case *corev1.PersistentVolumeClaim:
+		if o.Status.Phase == "" {
+			o.Status.Phase = corev1.ClaimBound
+		}
+	case *corev1.PersistentVolume:
+		if o.Status.Phase == "" {
+			o.Status.Phase = corev1.VolumeBound
+		}
+	}
+}
+
+func (b *pvBinder) Create(ctx context.Context, obj crclient.Object, opts ...crclient.CreateOption) error {
+	b.bind(obj)
+	return b.Client.Create(ctx, obj, opts...)
+}
+
+func (b *pvBinder) Update(ctx context.Context, obj crclient.Object, opts ...crclient.UpdateOption) error {
+	b.bind(obj)
+	return b.Client.Update(ctx, obj, opts...)
+}
+
+// unbound swaps the `r`econciler's client for the one wrapped by its pvBinder, so that nothing
+// gets Bound behind the test's back.
+func unbound(r *ReconcileSharedVolume) *ReconcileSharedVolume {
+	r.client = r.client.(*pvBinder).Client
+	return r
+}
+
 // mockReconciler returns a ReconcileSharedVolume with a mocked (as opposed to fake)
 // controller-runtime client. The mock client itself is returned so it can be EXPECT()ed, etc.
 // Use this when a fake client won't do, e.g. when you need to simulate an unexpected error.
 func mockReconciler(ctrl *gomock.Controller) (*ReconcileSharedVolume, *fixtures.MockClient) {
 	client := fixtures.NewMockClient(ctrl)
 	rsv := &ReconcileSharedVolume{
-		client: client,
+		client:    client,
+		apiReader: client,
 		// Scheme is unused, so leave it nil
+		// Events are discarded
+		recorder: &record.FakeRecorder{},
 	}
 	return rsv, client
 }
@@ -165,10 +216,7 @@ func validateResources(
 }
 
 func makeRequest(t *testing.T, sv *awsefsv1alpha1.SharedVolume) reconcile.Request {
-	nsname, err := crclient.ObjectKeyFromObject(sv)
-	if err != nil {
-		t.Fatal(err)
-	}
+	nsname := crclient.ObjectKeyFromObject(sv)
 	return reconcile.Request{
 		NamespacedName: nsname,
 	}
@@ -217,7 +265,7 @@ func TestReconcile(t *testing.T) {
 	}
 	req = makeRequest(t, sv1)
 	// Since the SV is new, the first reconcile loop just adds our finalizer and requeues
-	if res, err = r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	// Make sure the finalizer got added
@@ -238,7 +286,7 @@ func TestReconcile(t *testing.T) {
 	if sv1.Status.Phase != "" || sv1.Status.ClaimRef.Name != "" {
 		t.Fatalf("Expected uninitialized Status, but got %v", sv1.Status)
 	}
-	if res, err = r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	// And now it should be Pending
@@ -258,12 +306,12 @@ func TestReconcile(t *testing.T) {
 	// - Create the PV and PVC
 	// - Mark the status Ready with the reference to the PVC
 	// - Not requeue
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	validateResources(t, r.client, 1)
 	// Doing it again should be a no-op
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	validateResources(t, r.client, 1)
@@ -283,13 +331,13 @@ func TestReconcile(t *testing.T) {
 		t.Fatalf("Error creating SharedVolume: %v", err)
 	}
 	req = makeRequest(t, sv2)
-	if res, err = r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
-	if res, err = r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	svMap, _, _ = validateResources(t, r.client, 2)
@@ -303,7 +351,7 @@ func TestReconcile(t *testing.T) {
 		t.Fatal(err)
 	}
 	// This should ask to requeue so the next run through can take a greener path
-	if res, err = r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	// There should (still) be two of each resource, but let's check the SV by hand
@@ -335,7 +383,7 @@ func TestReconcile(t *testing.T) {
 		t.Fatal(err)
 	}
 	// This should ask to requeue so the next run through can take a greener path
-	if res, err = r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	// There should (still) be two of each resource, but let's check the SV by hand
@@ -358,7 +406,7 @@ func TestReconcile(t *testing.T) {
 	if err = r.client.Delete(ctx, pvMap[pvname]); err != nil {
 		t.Fatal(err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 
@@ -378,8 +426,8 @@ func TestReconcile(t *testing.T) {
 		if err := r.client.Delete(ctx, pvMap[pvname]); err != nil {
 			t.Fatal(err)
 		}
-		delete(pvBySharedVolume, svKey(svMap[fmt.Sprintf("%s/%s", nsy, svb)]))
-		if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+		pvBySharedVolume.remove(svKey(svMap[fmt.Sprintf("%s/%s", nsy, svb)]))
+		if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 			t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 		}
 		// validateResources proves the PV came back.
@@ -392,7 +440,7 @@ func TestReconcile(t *testing.T) {
 	if err = r.client.Update(ctx, pv); err != nil {
 		t.Fatal(err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	_, pvMap, _ = validateResources(t, r.client, 2)
@@ -408,7 +456,7 @@ func TestReconcile(t *testing.T) {
 	if err = r.client.Update(ctx, pv); err != nil {
 		t.Fatal(err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	_, pvMap, _ = validateResources(t, r.client, 2)
@@ -425,7 +473,7 @@ func TestReconcile(t *testing.T) {
 	if err = r.client.Update(ctx, pv); err != nil {
 		t.Fatal(err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	_, pvMap, _ = validateResources(t, r.client, 2)
@@ -442,7 +490,7 @@ func TestReconcile(t *testing.T) {
 	if err = r.client.Update(ctx, pv); err != nil {
 		t.Fatal(err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	svMap, pvMap, _ = validateResources(t, r.client, 2)
@@ -462,7 +510,7 @@ func TestReconcile(t *testing.T) {
 	if err = r.client.Update(ctx, sv2); err != nil {
 		t.Fatal(err)
 	}
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	// The PV and PVC should be gone, but the SV is still there
@@ -476,7 +524,7 @@ func TestReconcile(t *testing.T) {
 		t.Fatalf("Expected finalizers to be gone, but got %v", finalizers)
 	}
 	// Another reconcile at this stage should be a no-op
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	svMap, pvMap, pvcMap = getResources(t, r.client)
@@ -494,7 +542,7 @@ func TestReconcile(t *testing.T) {
 	}
 	validateResources(t, r.client, 1)
 	// This reconcile ought to hit our "deleted out of band" path, which is a no-op.
-	if res, err = r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err = r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	validateResources(t, r.client, 1)
@@ -511,7 +559,7 @@ func TestReconcileUnexpected(t *testing.T) {
 			Namespace: "bogus-namespace",
 		},
 	}
-	if res, err := r.Reconcile(rq); res != test.NullResult || err != nil {
+	if res, err := r.Reconcile(ctx, rq); res != test.NullResult || err != nil {
 		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
 	}
 	// Nothing should have been created.
@@ -536,9 +584,9 @@ func TestReconcileGetError(t *testing.T) {
 	theError := fixtures.AlreadyExists
 
 	// We don't especially care about the call args; they're validated in other tests
-	client.EXPECT().Get(ctx, nsname, gomock.Any()).Return(theError)
+	client.EXPECT().Get(withDeadline{}, nsname, gomock.Any()).Return(theError)
 
-	if res, err := r.Reconcile(req); res != test.NullResult || err != theError {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != theError {
 		t.Fatalf("Expected no requeue and error %v; got\nresult: %v\nerr: %v", theError, res, err)
 	}
 }
@@ -557,10 +605,7 @@ func TestUneditGetError(t *testing.T) {
 			Namespace: "bar",
 		},
 	}
-	svNSName, err := crclient.ObjectKeyFromObject(sv)
-	if err != nil {
-		t.Fatal(err)
-	}
+	svNSName := crclient.ObjectKeyFromObject(sv)
 
 	// The expected NamespacedName for the PV we'll try to retrieve. Hardcoded to avoid SHT.
 	pvname := "pv-bar-foo"
@@ -569,16 +614,16 @@ func TestUneditGetError(t *testing.T) {
 	}
 
 	gomock.InOrder(
-		client.EXPECT().Get(ctx, svNSName, &awsefsv1alpha1.SharedVolume{}).Do(
+		client.EXPECT().Get(withDeadline{}, svNSName, &awsefsv1alpha1.SharedVolume{}).Do(
 			// The Get() call populates the SharedVolume object
 			func(ctx context.Context, key crclient.ObjectKey, obj runtime.Object) {
 				*obj.(*awsefsv1alpha1.SharedVolume) = *sv
 			},
 		),
-		client.EXPECT().Get(ctx, pvNSName, &corev1.PersistentVolume{}).Return(fixtures.AlreadyExists),
+		client.EXPECT().Get(withDeadline{}, pvNSName, &corev1.PersistentVolume{}).Return(fixtures.AlreadyExists),
 	)
 
-	if res, err := r.Reconcile(makeRequest(t, sv)); res != test.NullResult || err != fixtures.AlreadyExists {
+	if res, err := r.Reconcile(ctx, makeRequest(t, sv)); res != test.NullResult || err != fixtures.AlreadyExists {
 		t.Fatalf("Expected no requeue and an error, but got\nresult: %v\nerr: %v", res, err)
 	}
 }
@@ -600,10 +645,7 @@ func TestUneditUpdateError(t *testing.T) {
 			FileSystemID:  "fs",
 		},
 	}
-	svNSName, err := crclient.ObjectKeyFromObject(sv)
-	if err != nil {
-		t.Fatal(err)
-	}
+	svNSName := crclient.ObjectKeyFromObject(sv)
 
 	// The PV we'll retrieve.
 	pve := pvEnsurable(sv)
@@ -614,24 +656,28 @@ func TestUneditUpdateError(t *testing.T) {
 	// The version of SharedVolume we expect to be passed to Update() will have that changed FSID
 	svUpdate := sv.DeepCopy()
 	svUpdate.Spec.FileSystemID = "abc123"
+	// ...and the PV name the reconciler worked out along the way
+	svUpdate.Status.VolumeName = pve.GetNamespacedName().Name
 
 	gomock.InOrder(
-		client.EXPECT().Get(ctx, svNSName, &awsefsv1alpha1.SharedVolume{}).Do(
+		client.EXPECT().Get(withDeadline{}, svNSName, &awsefsv1alpha1.SharedVolume{}).Do(
 			// The first Get() call populates the SharedVolume object
 			func(ctx context.Context, key crclient.ObjectKey, obj runtime.Object) {
 				*obj.(*awsefsv1alpha1.SharedVolume) = *sv
 			},
 		),
-		client.EXPECT().Get(ctx, pve.GetNamespacedName(), &corev1.PersistentVolume{}).Do(
-			// The second Get() populates the PersistentVolume object
+		// The second Get() looks for a PV with the legacy name. There isn't one.
+		client.EXPECT().Get(withDeadline{}, types.NamespacedName{Name: "pv-bar-foo"}, &corev1.PersistentVolume{}).Return(fixtures.NotFound),
+		client.EXPECT().Get(withDeadline{}, pve.GetNamespacedName(), &corev1.PersistentVolume{}).Do(
+			// The third Get() populates the PersistentVolume object
 			func(ctx context.Context, key crclient.ObjectKey, obj runtime.Object) {
 				*obj.(*corev1.PersistentVolume) = *pv
 			},
 		),
-		client.EXPECT().Update(ctx, svUpdate).Return(fixtures.NotFound),
+		client.EXPECT().Update(withDeadline{}, svUpdate).Return(fixtures.NotFound),
 	)
 
-	if res, err := r.Reconcile(makeRequest(t, sv)); res != test.NullResult || err != fixtures.NotFound {
+	if res, err := r.Reconcile(ctx, makeRequest(t, sv)); res != test.NullResult || err != fixtures.NotFound {
 		t.Fatalf("Expected no requeue and an error, but got\nresult: %v\nerr: %v", res, err)
 	}
 
@@ -653,6 +699,68 @@ func (m matchFinalizer) Matches(x interface{}) bool {
 	return false
 }
 
+// withDeadline matches the Context Reconcile passes down, which is bounded by
+// util.ReconcileTimeout.
+type withDeadline struct{}
+
+func (m withDeadline) String() string {
+	return "is a context with a deadline"
+}
+func (m withDeadline) Matches(x interface{}) bool {
+	c, ok := x.(context.Context)
+	if !ok {
+		return false
+	}
+	_, ok = c.Deadline()
+	return ok
+}
+
+// TestReconcileTimeout makes sure a hung API call doesn't wedge Reconcile past the
+// ReconcileTimeout.
+func TestReconcileTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, client := mockReconciler(ctrl)
+
+	saved := util.ReconcileTimeout
+	defer func() { util.ReconcileTimeout = saved }()
+	util.ReconcileTimeout = 10 * time.Millisecond
+
+	// The API server never answers
+	client.EXPECT().Get(withDeadline{}, gomock.Any(), &awsefsv1alpha1.SharedVolume{}).DoAndReturn(
+		func(c context.Context, _ types.NamespacedName, _ runtime.Object) error {
+			<-c.Done()
+			return c.Err()
+		})
+
+	sv := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}}
+	if res, err := r.Reconcile(ctx, makeRequest(t, sv)); res != test.NullResult || err != context.DeadlineExceeded {
+		t.Fatalf("Expected no requeue and a timeout, but got\nresult: %v\nerr: %v", res, err)
+	}
+}
+
+// TestReconcileCanceled makes sure reconciles whose context is canceled (because the manager is
+// stopping) bail out.
+func TestReconcileCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, client := mockReconciler(ctrl)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client.EXPECT().Get(withDeadline{}, gomock.Any(), &awsefsv1alpha1.SharedVolume{}).DoAndReturn(
+		func(c context.Context, _ types.NamespacedName, _ runtime.Object) error {
+			return c.Err()
+		})
+
+	sv := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}}
+	if res, err := r.Reconcile(canceled, makeRequest(t, sv)); res != test.NullResult || err != context.Canceled {
+		t.Fatalf("Expected no requeue and a cancellation, but got\nresult: %v\nerr: %v", res, err)
+	}
+}
+
 // TestFinalizerUpdateError covers the path where we fail to update the SharedVolume with
 // the finalizer.
 func TestFinalizerUpdateError(t *testing.T) {
@@ -670,14 +778,16 @@ func TestFinalizerUpdateError(t *testing.T) {
 
 	gomock.InOrder(
 		// First the reconciler gets the SharedVolume
-		client.EXPECT().Get(ctx, gomock.Any(), &awsefsv1alpha1.SharedVolume{}).Return(nil),
+		client.EXPECT().Get(withDeadline{}, gomock.Any(), &awsefsv1alpha1.SharedVolume{}).Return(nil),
+		// Then it looks for a PV with the legacy name. There isn't one.
+		client.EXPECT().Get(withDeadline{}, gomock.Any(), &corev1.PersistentVolume{}).Return(fixtures.NotFound),
 		// uneditSharedVolume checks for the PV. We'll say it's 404 to make unedit return quick.
-		client.EXPECT().Get(ctx, gomock.Any(), &corev1.PersistentVolume{}).Return(fixtures.NotFound),
+		client.EXPECT().Get(withDeadline{}, gomock.Any(), &corev1.PersistentVolume{}).Return(fixtures.NotFound),
 		// Now we add the finalizer and try to update; trigger the error there.
-		client.EXPECT().Update(ctx, matchFinalizer{}).Return(fixtures.NotFound),
+		client.EXPECT().Update(withDeadline{}, matchFinalizer{}).Return(fixtures.NotFound),
 	)
 
-	if res, err := r.Reconcile(makeRequest(t, sv)); res != test.NullResult || err != fixtures.NotFound {
+	if res, err := r.Reconcile(ctx, makeRequest(t, sv)); res != test.NullResult || err != fixtures.NotFound {
 		t.Fatalf("Expected no requeue and an error, but got\nresult: %v\nerr: %v", res, err)
 	}
 
@@ -696,9 +806,9 @@ func hijackEnsurable(rtype runtime.Object, sv *awsefsv1alpha1.SharedVolume, ensu
 	key := svKey(sv)
 	switch rtype.(type) {
 	case *corev1.PersistentVolume:
-		pvBySharedVolume[key] = ensurable
+		pvBySharedVolume.set(key, ensurable)
 	case *corev1.PersistentVolumeClaim:
-		pvcBySharedVolume[key] = ensurable
+		pvcBySharedVolume.set(key, ensurable)
 	default:
 		panic(fmt.Sprintf("rtype argument must be an instance of *PersistentVolume or *PersistentVolumeClaim; got %T", rtype))
 	}
@@ -718,6 +828,10 @@ func TestEnsureFails(t *testing.T) {
 			Name:      "sv",
 			Namespace: "proj1",
 		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID: "fsap-abc123abc123",
+			FileSystemID:  "fs-123abc",
+		},
 	}
 	if err := r.client.Create(ctx, sv); err != nil {
 		t.Fatal(err)
@@ -725,10 +839,10 @@ func TestEnsureFails(t *testing.T) {
 
 	req := makeRequest(t, sv)
 
-	if res, err := r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue result, no error, but got\nresult: %v\nerr: %v", res, err)
 	}
-	if res, err := r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Fatalf("Expected requeue result, no error, but got\nresult: %v\nerr: %v", res, err)
 	}
 
@@ -752,21 +866,31 @@ func TestEnsureFails(t *testing.T) {
 	mockPVCEnsurable := fixtures.NewMockEnsurable(ctrl)
 	hijackEnsurable(&corev1.PersistentVolumeClaim{}, sv, mockPVCEnsurable)
 
+	// Events are recorded against the SharedVolume
+	mockPVEnsurable.EXPECT().SetEventRecorder(r.recorder, gomock.Any()).AnyTimes()
+	mockPVCEnsurable.EXPECT().SetEventRecorder(r.recorder, gomock.Any()).AnyTimes()
+
 	// We'll do two runs through Reconcile()...
 	gomock.InOrder(
+		// Each run starts by looking for the PV and PVC, to see whether they need recovering.
+		// Neither exists.
+		mockPVEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{Name: "pv"}),
+		mockPVCEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{Namespace: "proj1", Name: "pvc"}),
 		// On the first run, we'll make the PV's Ensure fail
 		mockPVEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{}),
-		mockPVEnsurable.EXPECT().Ensure(gomock.Any(), gomock.Any()).Return(fixtures.NotFound),
+		mockPVEnsurable.EXPECT().Ensure(gomock.Any(), gomock.Any(), gomock.Any()).Return(fixtures.NotFound),
+		mockPVEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{Name: "pv"}),
+		mockPVCEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{Namespace: "proj1", Name: "pvc"}),
 		// On the second run, make it pass so we get to the PVC's Ensure
 		mockPVEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{}),
-		mockPVEnsurable.EXPECT().Ensure(gomock.Any(), gomock.Any()).Return(nil),
+		mockPVEnsurable.EXPECT().Ensure(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
 		// Make PVC's Ensure fail. (Use a different error so we can distinguish.)
 		mockPVCEnsurable.EXPECT().GetNamespacedName().Return(types.NamespacedName{}),
-		mockPVCEnsurable.EXPECT().Ensure(gomock.Any(), gomock.Any()).Times(1).Return(fixtures.AlreadyExists),
+		mockPVCEnsurable.EXPECT().Ensure(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(fixtures.AlreadyExists),
 	)
 
 	// Do the first run. The NotFound error bubbles up from the PV's Ensure().
-	if res, err := r.Reconcile(req); res != test.NullResult || err != fixtures.NotFound {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.NotFound {
 		t.Errorf("Expected no requeue and a error, got\nresult: %v\nerr: %v", res, err)
 	}
 	// That should have caused Reconcile to set the SharedVolume's Status to Failed
@@ -779,8 +903,10 @@ func TestEnsureFails(t *testing.T) {
 	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed || sv.Status.Message != "NotFound" {
 		t.Errorf("Expected Failed Phase and NotFound Message but got %v", sv)
 	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionFalse, reasonEnsureFailed)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reasonEnsureFailed)
 
-	if res, err := r.Reconcile(req); res != test.NullResult || err != fixtures.AlreadyExists {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.AlreadyExists {
 		t.Errorf("Expected no requeue and a error, got\nresult: %v\nerr: %v", res, err)
 	}
 	// Note that the PV (and PVC) still hasn't been created because we mocked the guts out of its Ensure
@@ -794,13 +920,16 @@ func TestEnsureFails(t *testing.T) {
 	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed || sv.Status.Message != "AlreadyExists" {
 		t.Errorf("Expected Failed Phase and NotFound Message but got %v", sv)
 	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionTrue, reasonCreated)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionFalse, reasonEnsureFailed)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reasonEnsureFailed)
 }
 
 // TestHandleDeleteFails hits unusual failure paths in `handleDelete`
 func TestHandleDeleteFails(t *testing.T) {
 	// Make sure the caches are cleared from other tests
-	pvBySharedVolume = make(map[string]util.Ensurable)
-	pvcBySharedVolume = make(map[string]util.Ensurable)
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
 
 	r := fakeReconciler()
 	// We'll use this later to wrap the fake client to make it error where we want it
@@ -824,13 +953,13 @@ func TestHandleDeleteFails(t *testing.T) {
 	// It takes three Reconciles to get to steady state. This sequence is validated thoroughly in
 	// TestReconcile, so just rough it up here.
 	req := makeRequest(t, sv)
-	if res, err := r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Errorf("Expected requeue and no error, got\nresult: %v\nerr: %v", res, err)
 	}
-	if res, err := r.Reconcile(req); res != test.RequeueResult || err != nil {
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
 		t.Errorf("Expected requeue and no error, got\nresult: %v\nerr: %v", res, err)
 	}
-	if res, err := r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Errorf("Expected no requeue and a error, got\nresult: %v\nerr: %v", res, err)
 	}
 	// This proves our SV/PV/PVC are all present and accounted for
@@ -839,10 +968,10 @@ func TestHandleDeleteFails(t *testing.T) {
 
 	// Let's also make sure the caches are warm
 	svk := svKey(sv)
-	if _, ok := pvcBySharedVolume[svk]; !ok {
+	if _, ok := pvcBySharedVolume.get(svk); !ok {
 		t.Fatal("Expected the PVC cache to be warm")
 	}
-	if _, ok := pvBySharedVolume[svk]; !ok {
+	if _, ok := pvBySharedVolume.get(svk); !ok {
 		t.Fatal("Expected the PV cache to be warm")
 	}
 
@@ -862,14 +991,14 @@ func TestHandleDeleteFails(t *testing.T) {
 			fixtures.AlreadyExists,
 		},
 	}
-	if res, err := r.Reconcile(req); res != test.NullResult || err != fixtures.AlreadyExists {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.AlreadyExists {
 		t.Fatalf("Expected null result, AlreadyExists error, but got\nresult: %v\nerr: %v", res, err)
 	}
 	// The PVC should be gone from the cache, but the PV should not
-	if _, ok := pvcBySharedVolume[svk]; ok {
+	if _, ok := pvcBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PVC cache to be clear")
 	}
-	if _, ok := pvBySharedVolume[svk]; !ok {
+	if _, ok := pvBySharedVolume.get(svk); !ok {
 		t.Fatal("Expected the PV cache to be warm")
 	}
 	// All three resources should still be there
@@ -893,14 +1022,14 @@ func TestHandleDeleteFails(t *testing.T) {
 			fixtures.AlreadyExists,
 		},
 	}
-	if res, err := r.Reconcile(req); res != test.NullResult || err != fixtures.AlreadyExists {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.AlreadyExists {
 		t.Fatalf("Expected null result, AlreadyExists error, but got\nresult: %v\nerr: %v", res, err)
 	}
 	// Both the PVC and the PV should be gone from the cache
-	if _, ok := pvcBySharedVolume[svk]; ok {
+	if _, ok := pvcBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PVC cache to be clear")
 	}
-	if _, ok := pvBySharedVolume[svk]; ok {
+	if _, ok := pvBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PV cache to be clear")
 	}
 	// But only the PVC got deleted
@@ -925,14 +1054,14 @@ func TestHandleDeleteFails(t *testing.T) {
 			fixtures.AlreadyExists,
 		},
 	}
-	if res, err := r.Reconcile(req); res != test.NullResult || err != fixtures.AlreadyExists {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.AlreadyExists {
 		t.Fatalf("Expected null result, AlreadyExists error, but got\nresult: %v\nerr: %v", res, err)
 	}
 	// Both the PVC and the PV should be gone from the cache
-	if _, ok := pvcBySharedVolume[svk]; ok {
+	if _, ok := pvcBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PVC cache to be clear")
 	}
-	if _, ok := pvBySharedVolume[svk]; ok {
+	if _, ok := pvBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PV cache to be clear")
 	}
 	// And both got deleted
@@ -950,14 +1079,14 @@ func TestHandleDeleteFails(t *testing.T) {
 	//    We start off in a messy state where the PV and PVC are already gone, so this isn't
 	//    *exactly* a green path. More... chartreuse.
 	r.client = realFakeClient
-	if res, err := r.Reconcile(req); res != test.NullResult || err != nil {
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
 		t.Fatalf("Expected null result, no error, but got\nresult: %v\nerr: %v", res, err)
 	}
 	// Both the PVC and the PV should be gone from the cache
-	if _, ok := pvcBySharedVolume[svk]; ok {
+	if _, ok := pvcBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PVC cache to be clear")
 	}
-	if _, ok := pvBySharedVolume[svk]; ok {
+	if _, ok := pvBySharedVolume.get(svk); ok {
 		t.Fatal("Expected the PV cache to be clear")
 	}
 	// And both got deleted
@@ -972,6 +1101,246 @@ func TestHandleDeleteFails(t *testing.T) {
 	}
 }
 
+// TestInvalidSpec makes sure a SharedVolume with an inconsistent Spec (which the webhook would
+// normally have rejected) goes Failed without creating anything.
+func TestInvalidSpec(t *testing.T) {
+	r := fakeReconciler()
+
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			FileSystemID: "fs-123abc",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+
+	// The first pass adds the finalizer
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	// The second fails validation, and doesn't requeue
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	if len(svMap) != 1 || len(pvMap) != 0 || len(pvcMap) != 0 {
+		t.Fatalf("Expected only our SharedVolume resource, but got\nSharedVolumes: %s\nPVs: %s\nPVCs: %s",
+			svMap, pvMap, pvcMap)
+	}
+	sv = svMap["proj1/sv"]
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed ||
+		sv.Status.Message != "spec.accessPointID: Required value: one of accessPointID, accessPoint or source must be specified" {
+		t.Fatalf("Expected Failed Phase with a validation Message but got %v", format(sv.Status))
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reasonInvalidSpec)
+}
+
+// TestProvisionAccessPoint covers the lifecycle of a SharedVolume for which the operator
+// provisions (and, per the ReclaimPolicy, deletes) the access point.
+func TestProvisionAccessPoint(t *testing.T) {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	r := fakeReconciler()
+	efsClient := efs.NewFakeClient()
+	r.efsClient = efsClient
+
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+			// The fake client doesn't set this, and we use it as the ClientToken.
+			UID: "4f1b5c2a-1d4e-4f7a-9d3c-000000000001",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			FileSystemID: "fs-123abc",
+			AccessPoint: &awsefsv1alpha1.AccessPointSpec{
+				PosixUser: awsefsv1alpha1.PosixUser{UID: 1000, GID: 1000},
+				RootDirectory: &awsefsv1alpha1.RootDirectory{
+					Path: "/proj1",
+					CreationInfo: &awsefsv1alpha1.CreationInfo{
+						OwnerUID:    1000,
+						OwnerGID:    1000,
+						Permissions: "0755",
+					},
+				},
+				ReclaimPolicy: awsefsv1alpha1.AccessPointDelete,
+			},
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+
+	// Finalizer, then Pending
+	for i := 0; i < 2; i++ {
+		if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+			t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+
+	// Make provisioning fail the first time
+	efsClient.CreateError = fixtures.AlreadyExists
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.AlreadyExists {
+		t.Fatalf("Expected no requeue, AlreadyExists error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, _ := getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed || sv.Status.AccessPointID != "" || len(pvMap) != 0 {
+		t.Fatalf("Expected Failed Phase, no access point, and no PV, but got\n%sPVs: %s", format(sv.Status), pvMap)
+	}
+
+	// Now let it work. This pass provisions the access point and requeues.
+	efsClient.CreateError = nil
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	if len(efsClient.AccessPoints) != 1 {
+		t.Fatalf("Expected one access point but got %v", efsClient.AccessPoints)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	apid := sv.Status.AccessPointID
+	apreq, ok := efsClient.AccessPoints[apid]
+	if !ok {
+		t.Fatalf("Expected Status.AccessPointID to refer to the provisioned access point but got %q", apid)
+	}
+	if apreq.ClientToken != string(sv.UID) || apreq.FileSystemID != "fs-123abc" ||
+		apreq.Spec.RootDirectory.Path != "/proj1" ||
+		apreq.Tags[svOwnerNamespaceKey] != "proj1" || apreq.Tags[svOwnerNameKey] != "sv" {
+		t.Fatalf("Unexpected access point request: %s", format(apreq))
+	}
+
+	// This pass creates the PV and PVC, pointing at the provisioned access point
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, _ = validateResources(t, r.client, 1)
+	pv := pvMap["/"+pvNameForSharedVolume(sv)]
+	if expect := "fs-123abc::" + apid; pv.Spec.CSI.VolumeHandle != expect {
+		t.Fatalf("Expected VolumeHandle %q but got %q", expect, pv.Spec.CSI.VolumeHandle)
+	}
+	// Doing it again is a no-op; in particular, uneditSharedVolume doesn't try to copy the access
+	// point ID into the Spec.
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = validateResources(t, r.client, 1)
+	sv = svMap["proj1/sv"]
+	if sv.Spec.AccessPointID != "" {
+		t.Fatalf("Expected Spec.AccessPointID to remain empty but got %q", sv.Spec.AccessPointID)
+	}
+
+	// Delete. Make the access point deletion fail the first time.
+	delTime := metav1.Now()
+	sv.DeletionTimestamp = &delTime
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	efsClient.DeleteError = fixtures.NotFound
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != fixtures.NotFound {
+		t.Fatalf("Expected no requeue, NotFound error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	if len(pvMap) != 0 || len(pvcMap) != 0 || len(svMap["proj1/sv"].GetFinalizers()) != 1 {
+		t.Fatalf("Expected PV and PVC to be gone but the finalizer to remain, but got\nSharedVolumes: %s\nPVs: %s\nPVCs: %s",
+			svMap, pvMap, pvcMap)
+	}
+	if len(efsClient.AccessPoints) != 1 {
+		t.Fatalf("Expected the access point to remain but got %v", efsClient.AccessPoints)
+	}
+	efsClient.DeleteError = nil
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	if len(svMap["proj1/sv"].GetFinalizers()) != 0 {
+		t.Fatalf("Expected the finalizer to be gone but got %v", svMap["proj1/sv"].GetFinalizers())
+	}
+	if len(efsClient.AccessPoints) != 0 {
+		t.Fatalf("Expected the access point to be deleted but got %v", efsClient.AccessPoints)
+	}
+}
+
+// TestReclaimAccessPoint covers the reclaimAccessPoint paths not reachable via Reconcile with the
+// fake client.
+func TestReclaimAccessPoint(t *testing.T) {
+	r := fakeReconciler()
+	efsClient := efs.NewFakeClient()
+	r.efsClient = efsClient
+	logger := log.WithName("test")
+
+	apSpec := awsefsv1alpha1.AccessPointSpec{PosixUser: awsefsv1alpha1.PosixUser{UID: 1, GID: 1}}
+	apid, err := efsClient.CreateAccessPoint(ctx, efs.AccessPointRequest{ClientToken: "tok", Spec: apSpec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			FileSystemID: "fs-123abc",
+			AccessPoint:  &apSpec,
+		},
+		Status: awsefsv1alpha1.SharedVolumeStatus{
+			AccessPointID: apid,
+		},
+	}
+
+	// With the default (Retain) policy, nothing happens
+	if err := r.reclaimAccessPoint(ctx, logger, sv); err != nil {
+		t.Fatal(err)
+	}
+	if len(efsClient.AccessPoints) != 1 {
+		t.Fatalf("Expected the access point to be retained but got %v", efsClient.AccessPoints)
+	}
+
+	// With Delete, the access point isn't deleted while the PV still exists...
+	sv.Spec.AccessPoint.ReclaimPolicy = awsefsv1alpha1.AccessPointDelete
+	if err := r.client.Create(ctx, pvDefinition(sv)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reclaimAccessPoint(ctx, logger, sv); err == nil {
+		t.Fatal("Expected an error while the PV still exists")
+	}
+	if len(efsClient.AccessPoints) != 1 {
+		t.Fatalf("Expected the access point to remain but got %v", efsClient.AccessPoints)
+	}
+	// ...or if we can't tell...
+	realFakeClient := r.client
+	r.client = &test.FakeClientWithCustomErrors{
+		Client:      realFakeClient,
+		GetBehavior: []error{fixtures.AlreadyExists},
+	}
+	if err := r.reclaimAccessPoint(ctx, logger, sv); err != fixtures.AlreadyExists {
+		t.Fatalf("Expected AlreadyExists but got %v", err)
+	}
+	if len(efsClient.AccessPoints) != 1 {
+		t.Fatalf("Expected the access point to remain but got %v", efsClient.AccessPoints)
+	}
+	// ...but is once the PV is gone.
+	r.client = realFakeClient
+	if err := r.client.Delete(ctx, pvDefinition(sv)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reclaimAccessPoint(ctx, logger, sv); err != nil {
+		t.Fatal(err)
+	}
+	if len(efsClient.AccessPoints) != 0 {
+		t.Fatalf("Expected the access point to be deleted but got %v", efsClient.AccessPoints)
+	}
+}
+
 // TestUpdateStatusFail covers the `updateStatus` path where the Update fails.
 func TestUpdateStatusFail(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -997,7 +1366,354 @@ func TestUpdateStatusFail(t *testing.T) {
 		client.EXPECT().Update(ctx, sv).Return(fixtures.AlreadyExists),
 		logger.EXPECT().Error(fixtures.AlreadyExists, "Failed to update SharedVolume status"),
 	)
-	if err := r.updateStatus(logger, sv); err != fixtures.AlreadyExists {
+	if err := r.updateStatus(ctx, logger, sv); err != fixtures.AlreadyExists {
 		t.Fatalf("Expected AlreadyExists but got %v", err)
 	}
 }
+
+// TestClaimName covers a SharedVolume asking for a specific claim name, including reverting an
+// edit to it.
+func TestClaimName(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r, func(sv *awsefsv1alpha1.SharedVolume) {
+		sv.Spec.ClaimName = "data"
+		sv.Spec.ClaimLabels = map[string]string{"backup": "daily"}
+	})
+
+	svMap, _, pvcMap := getResources(t, r.client)
+	pvc, ok := pvcMap["proj1/data"]
+	if !ok {
+		t.Fatalf("Expected PVC proj1/data but got %s", pvcMap)
+	}
+	if pvc.Labels["backup"] != "daily" {
+		t.Fatalf("Expected the requested label on the PVC but got %v", pvc.Labels)
+	}
+	sv := svMap["proj1/sv"]
+	if sv.Status.ClaimRef.Name != "data" {
+		t.Fatalf("Expected claimRef to name the PVC but got %s", format(sv.Status.ClaimRef))
+	}
+
+	// Somebody edits the claim name behind the webhook's back
+	sv.Spec.ClaimName = "other"
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = validateResources(t, r.client, 1)
+	if sv = svMap["proj1/sv"]; sv.Spec.ClaimName != "data" {
+		t.Fatalf("Expected the claim name to be reverted but got %q", sv.Spec.ClaimName)
+	}
+}
+
+// TestClaimMetadataEdit makes sure edits to the claim labels and annotations are reverted, like
+// the rest of the spec, rather than silently ignored.
+func TestClaimMetadataEdit(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r, func(sv *awsefsv1alpha1.SharedVolume) {
+		sv.Spec.ClaimLabels = map[string]string{"backup": "daily"}
+		sv.Spec.ClaimAnnotations = map[string]string{"example.com/owner": "team-a"}
+	})
+
+	// The PV controller annotates the PV when it binds it; that's not an edit.
+	_, pvMap, _ := getResources(t, r.client)
+	pv := pvMap["/pv-proj1-sv-ee8069eb38"]
+	pv.Annotations["pv.kubernetes.io/bound-by-controller"] = "yes"
+	if err := r.client.Update(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+
+	// Somebody edits them behind the webhook's back
+	svMap, _, _ := getResources(t, r.client)
+	sv := svMap["proj1/sv"]
+	sv.Spec.ClaimLabels = map[string]string{"backup": "weekly", "tier": "gold"}
+	sv.Spec.ClaimAnnotations = nil
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = validateResources(t, r.client, 1)
+	sv = svMap["proj1/sv"]
+	if expected := map[string]string{"backup": "daily"}; !reflect.DeepEqual(sv.Spec.ClaimLabels, expected) {
+		t.Fatalf("Expected the claim labels to be reverted to %v but got %v", expected, sv.Spec.ClaimLabels)
+	}
+	if expected := map[string]string{"example.com/owner": "team-a"}; !reflect.DeepEqual(sv.Spec.ClaimAnnotations, expected) {
+		t.Fatalf("Expected the claim annotations to be reverted to %v but got %v", expected, sv.Spec.ClaimAnnotations)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+}
+
+// TestClaimNameConflict makes sure we don't touch a PVC we don't own that has the claim name a
+// SharedVolume asks for.
+func TestClaimNameConflict(t *testing.T) {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	r := fakeReconciler()
+	theirs := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "data",
+			Namespace: "proj1",
+			Labels:    map[string]string{"app": "theirs"},
+		},
+	}
+	if err := r.client.Create(ctx, theirs); err != nil {
+		t.Fatal(err)
+	}
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID: "fsap-abc123abc123",
+			FileSystemID:  "fs-123abc",
+			ClaimName:     "data",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+
+	// Finalizer, Pending, then the conflict, which we keep checking.
+	for _, expected := range []reconcile.Result{test.RequeueResult, test.RequeueResult, test.RequeueResult, test.RequeueResult} {
+		if res, err := r.Reconcile(ctx, req); res != expected || err != nil {
+			t.Fatalf("Expected %v, no error; got\nresult: %v\nerr: %v", expected, res, err)
+		}
+	}
+	// Their PVC is untouched, and we didn't create a PV.
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	if len(pvMap) != 0 || len(pvcMap) != 1 {
+		t.Fatalf("Expected no PVs and one PVC but got\nPVs: %s\nPVCs: %s", pvMap, pvcMap)
+	}
+	if pvc := pvcMap["proj1/data"]; !reflect.DeepEqual(pvc.Labels, theirs.Labels) || pvc.Spec.VolumeName != "" {
+		t.Fatalf("Expected their PVC to be untouched but got %s", format(pvc))
+	}
+	sv = svMap["proj1/sv"]
+	expectMessage := "PersistentVolumeClaim data already exists and does not belong to this SharedVolume"
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed || sv.Status.Message != expectMessage {
+		t.Fatalf("Expected Failed phase with message %q but got %s", expectMessage, format(sv.Status))
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCCreated, metav1.ConditionFalse, reasonClaimNameConflict)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reasonClaimNameConflict)
+
+	// Deleting the SharedVolume leaves their PVC alone too.
+	delTime := metav1.Now()
+	sv.DeletionTimestamp = &delTime
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, pvcMap = getResources(t, r.client)
+	if len(pvcMap) != 1 {
+		t.Fatalf("Expected their PVC to survive but got %s", pvcMap)
+	}
+	if finalizers := svMap["proj1/sv"].GetFinalizers(); len(finalizers) != 0 {
+		t.Fatalf("Expected finalizer to be gone but found %v", finalizers)
+	}
+}
+
+// TestReadOnly covers a read-only SharedVolume, including reverting an edit to make it writable.
+func TestReadOnly(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r, func(sv *awsefsv1alpha1.SharedVolume) {
+		sv.Spec.ReadOnly = true
+	})
+
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	pv := pvMap["/pv-proj1-sv-ee8069eb38"]
+	if !pv.Spec.CSI.ReadOnly || !reflect.DeepEqual(pv.Spec.AccessModes, []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}) {
+		t.Fatalf("Expected a read-only PV but got %s", format(pv.Spec))
+	}
+	pvc := pvcMap["proj1/pvc-sv"]
+	if !reflect.DeepEqual(pvc.Spec.AccessModes, []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}) {
+		t.Fatalf("Expected a read-only PVC but got %s", format(pvc.Spec))
+	}
+
+	// Somebody tries to make it writable behind the webhook's back
+	sv := svMap["proj1/sv"]
+	sv.Spec.ReadOnly = false
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	if sv = svMap["proj1/sv"]; !sv.Spec.ReadOnly {
+		t.Fatal("Expected readOnly to be reverted")
+	}
+}
+
+// TestMountOptions covers a SharedVolume asking for TLS and IAM authorization, including reverting
+// an edit to turn them off.
+func TestMountOptions(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r, func(sv *awsefsv1alpha1.SharedVolume) {
+		sv.Spec.EncryptInTransit = true
+		sv.Spec.IAMAuthorization = true
+	})
+
+	svMap, pvMap, _ := getResources(t, r.client)
+	pv := pvMap["/pv-proj1-sv-ee8069eb38"]
+	if !reflect.DeepEqual(pv.Spec.MountOptions, []string{"tls", "iam"}) {
+		t.Fatalf("Expected tls and iam mount options but got %v", pv.Spec.MountOptions)
+	}
+
+	// Somebody tries to turn them off behind the webhook's back
+	sv := svMap["proj1/sv"]
+	sv.Spec.EncryptInTransit = false
+	sv.Spec.IAMAuthorization = false
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	if sv = svMap["proj1/sv"]; !sv.Spec.EncryptInTransit || !sv.Spec.IAMAuthorization {
+		t.Fatalf("Expected mount options to be reverted but got %s", format(sv.Spec))
+	}
+}
+
+// TestLegacyPVName covers a SharedVolume created by an older version of the operator, whose PV has
+// the legacy name. It must keep using that PV rather than creating a new one.
+func TestLegacyPVName(t *testing.T) {
+	r := fakeReconciler()
+	req := readySharedVolume(t, r)
+
+	// Make it look like the old operator made the PV and PVC.
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	sv := svMap["proj1/sv"]
+	pv := pvMap["/"+sv.Status.VolumeName]
+	pvc := pvcMap["proj1/pvc-sv"]
+	if err := r.client.Delete(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	pv.Name = "pv-proj1-sv"
+	pv.ResourceVersion = ""
+	if err := r.client.Create(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+	pvc.Spec.VolumeName = "pv-proj1-sv"
+	if err := r.client.Update(ctx, pvc); err != nil {
+		t.Fatal(err)
+	}
+	sv.Status.VolumeName = ""
+	if err := r.client.Status().Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	// The first pass records the name...
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	if name := svMap["proj1/sv"].Status.VolumeName; name != "pv-proj1-sv" {
+		t.Fatalf("Expected the legacy PV name to be recorded but got %q", name)
+	}
+	// ...and after that it's business as usual.
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	if _, pvMap, _ = validateResources(t, r.client, 1); pvMap["/pv-proj1-sv"] == nil {
+		t.Fatalf("Expected the legacy PV to be kept but got %s", pvMap)
+	}
+}
+
+// TestDiscoverPVName makes sure a PV with the legacy name is only adopted if it belongs to the
+// SharedVolume. Under the old naming scheme, proj1-a/b and proj1/a-b would both have used pv-proj1-a-b.
+func TestDiscoverPVName(t *testing.T) {
+	r := fakeReconciler()
+	theirs := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Namespace: "proj1-a", Name: "b"}}
+	pv := pvEnsurable(theirs).(*util.EnsurableImpl).Definition.(*corev1.PersistentVolume).DeepCopy()
+	pv.Name = legacyPVName(theirs)
+	if err := r.client.Create(ctx, pv); err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := r.discoverPVName(ctx, theirs); name != "pv-proj1-a-b" || err != nil {
+		t.Fatalf("Expected the legacy PV name, no error; got\nname: %s\nerr: %v", name, err)
+	}
+	ours := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Namespace: "proj1", Name: "a-b"}}
+	if name, err := r.discoverPVName(ctx, ours); name != newPVName(ours) || err != nil {
+		t.Fatalf("Expected a new PV name, no error; got\nname: %s\nerr: %v", name, err)
+	}
+}
+
+// TestPVNameConflict covers a PV with the SharedVolume's PV name that belongs to someone else.
+func TestPVNameConflict(t *testing.T) {
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	r := fakeReconciler()
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID: "fsap-abc123abc123",
+			FileSystemID:  "fs-123abc",
+		},
+	}
+	foreign := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: newPVName(sv)}}
+	if err := r.client.Create(ctx, foreign); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+	// Finalizer, Pending, then the conflict, which keeps requeueing.
+	for i := 0; i < 3; i++ {
+		if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+			t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+	svMap, pvMap, pvcMap := getResources(t, r.client)
+	sv = svMap["proj1/sv"]
+	message := fmt.Sprintf("PersistentVolume %s already exists and does not belong to this SharedVolume", foreign.Name)
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed || sv.Status.Message != message {
+		t.Fatalf("Expected Failed phase with message %q but got %s", message, format(sv.Status))
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumePVCreated, metav1.ConditionFalse, reasonVolumeNameConflict)
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reasonVolumeNameConflict)
+	if len(pvMap) != 1 || len(pvcMap) != 0 {
+		t.Fatalf("Expected the foreign PV to be left alone and no PVC but got\nPVs: %s\nPVCs: %s", pvMap, pvcMap)
+	}
+	if pv := pvMap["/"+foreign.Name]; pv.Spec.CSI != nil {
+		t.Fatalf("Expected the foreign PV to be left alone but got %s", format(pv))
+	}
+
+	// Deleting the SharedVolume leaves the foreign PV alone too.
+	delTime := metav1.Now()
+	sv.DeletionTimestamp = &delTime
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, _ = getResources(t, r.client)
+	if finalizers := svMap["proj1/sv"].GetFinalizers(); len(finalizers) != 0 {
+		t.Fatalf("Expected finalizers to be gone, but got %v", finalizers)
+	}
+	if len(pvMap) != 1 {
+		t.Fatalf("Expected the foreign PV to survive but got %s", pvMap)
+	}
+}
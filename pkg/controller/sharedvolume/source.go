@@ -0,0 +1,50 @@
+package sharedvolume
+
+// Helpers for SharedVolumes taking their file system and access point from a SharedVolumeSource.
+
+import (
+	"context"
+	"fmt"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resolveSource looks up the SharedVolumeSource named by the `sharedVolume`'s Spec.Source and
+// checks that the SharedVolume's namespace is allowed to use it. If so, and the source's IDs aren't
+// yet recorded in the Status, it records them and pushes the update. The returned `reason` and
+// `message` are non-empty if the source can't be used, in which case the caller should mark the
+// SharedVolume Failed. The `bool` return indicates whether the Status was updated.
+// The allow-list is checked every time, so a namespace dropped from it stops being reconciled,
+// but the IDs are only recorded once: the PV can't be changed after it's created anyway.
+func (r *ReconcileSharedVolume) resolveSource(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) (
+	updated bool, reason, message string, err error) {
+
+	source := &awsefsv1alpha1.SharedVolumeSource{}
+	if err = r.client.Get(ctx, types.NamespacedName{Name: sharedVolume.Spec.Source}, source); err != nil {
+		if errors.IsNotFound(err) {
+			return false, reasonSourceNotFound,
+				fmt.Sprintf("SharedVolumeSource %s does not exist", sharedVolume.Spec.Source), nil
+		}
+		logger.Error(err, "Failed to retrieve SharedVolumeSource", "SharedVolumeSource", sharedVolume.Spec.Source)
+		return false, "", "", err
+	}
+	if !source.AllowsNamespace(sharedVolume.Namespace) {
+		return false, reasonSourceNotAllowed,
+			fmt.Sprintf("SharedVolumeSource %s may not be used in namespace %s", source.Name, sharedVolume.Namespace), nil
+	}
+	if sharedVolume.Status.FileSystemID != "" {
+		return false, "", "", nil
+	}
+	logger.Info("Resolved SharedVolumeSource", "SharedVolumeSource", source.Name,
+		"FileSystemID", source.Spec.FileSystemID, "AccessPointID", source.Spec.AccessPointID)
+	sharedVolume.Status.FileSystemID = source.Spec.FileSystemID
+	sharedVolume.Status.AccessPointID = source.Spec.AccessPointID
+	if err = r.updateStatus(ctx, logger, sharedVolume); err != nil {
+		return false, "", "", err
+	}
+	return true, "", "", nil
+}
@@ -0,0 +1,275 @@
+package sharedvolume
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/test"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func mkSource(name string, allowedNamespaces ...string) *awsefsv1alpha1.SharedVolumeSource {
+	return &awsefsv1alpha1.SharedVolumeSource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: awsefsv1alpha1.SharedVolumeSourceSpec{
+			FileSystemID:      "fs-5005ce",
+			AccessPointID:     "fsap-5005ce5005ce",
+			AllowedNamespaces: allowedNamespaces,
+		},
+	}
+}
+
+// expectFailure reconciles and checks that the SharedVolume is Failed for `reason`, with
+// `message`.
+func expectFailure(t *testing.T, r *ReconcileSharedVolume, req reconcile.Request, reason, message string) {
+	t.Helper()
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ := getResources(t, r.client)
+	sv := svMap["proj1/sv"]
+	if sv.Status.Phase != awsefsv1alpha1.SharedVolumeFailed || sv.Status.Message != message {
+		t.Fatalf("Expected Failed phase with message %q but got %s", message, format(sv.Status))
+	}
+	checkCondition(t, sv, awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionTrue, reason)
+}
+
+// TestSharedVolumeSource covers a SharedVolume taking its file system and access point from a
+// SharedVolumeSource, including the source not existing, or not allowing the namespace.
+func TestSharedVolumeSource(t *testing.T) {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	r := fakeReconciler()
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			Source: "shared",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+	// Finalizer, then Pending
+	for i := 0; i < 2; i++ {
+		if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+			t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+
+	// No such source yet
+	expectFailure(t, r, req, reasonSourceNotFound, "SharedVolumeSource shared does not exist")
+
+	// The source shows up, but isn't for us
+	source := mkSource("shared", "proj2")
+	if err := r.client.Create(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	expectFailure(t, r, req, reasonSourceNotAllowed, "SharedVolumeSource shared may not be used in namespace proj1")
+
+	// Now it is. The first pass records the IDs...
+	source.Spec.AllowedNamespaces = append(source.Spec.AllowedNamespaces, "proj1")
+	if err := r.client.Update(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ := getResources(t, r.client)
+	if status := svMap["proj1/sv"].Status; status.FileSystemID != "fs-5005ce" || status.AccessPointID != "fsap-5005ce5005ce" {
+		t.Fatalf("Expected the source's IDs in the Status but got %s", format(status))
+	}
+	// ...and the second creates the PV and PVC.
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, _ := validateResources(t, r.client, 1)
+	if handle := pvMap["/pv-proj1-sv-ee8069eb38"].Spec.CSI.VolumeHandle; handle != "fs-5005ce::fsap-5005ce5005ce" {
+		t.Fatalf("Expected the PV to use the source's IDs but got VolumeHandle %q", handle)
+	}
+	checkCondition(t, svMap["proj1/sv"], awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected)
+
+	// Editing the IDs into the Spec behind the webhook's back gets reverted
+	sv = svMap["proj1/sv"]
+	sv.Spec.FileSystemID = "fs-5005ce"
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	if sv = svMap["proj1/sv"]; sv.Spec.FileSystemID != "" {
+		t.Fatalf("Expected fileSystemID to be reverted but got %s", format(sv.Spec))
+	}
+
+	// Taking the namespace off the allow-list fails the SharedVolume, but leaves the PV and PVC
+	// alone.
+	source.Spec.AllowedNamespaces = []string{"proj2"}
+	if err := r.client.Update(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	expectFailure(t, r, req, reasonSourceNotAllowed, "SharedVolumeSource shared may not be used in namespace proj1")
+	if _, pvMap, pvcMap := getResources(t, r.client); len(pvMap) != 1 || len(pvcMap) != 1 {
+		t.Fatalf("Expected the PV and PVC to be left alone but got\nPVs: %s\nPVCs: %s", pvMap, pvcMap)
+	}
+}
+
+func TestSourceToSharedVolumes(t *testing.T) {
+	r := fakeReconciler()
+	for _, sv := range []*awsefsv1alpha1.SharedVolume{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "sv1", Namespace: "proj1"},
+			Spec:       awsefsv1alpha1.SharedVolumeSpec{Source: "shared"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "sv2", Namespace: "proj1"},
+			Spec:       awsefsv1alpha1.SharedVolumeSpec{Source: "other"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "sv3", Namespace: "proj1"},
+			Spec:       awsefsv1alpha1.SharedVolumeSpec{FileSystemID: "fs-123abc", AccessPointID: "fsap-abc123abc123"},
+		},
+	} {
+		if err := r.client.Create(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mapper := sourceToSharedVolumes(r.client)
+
+	source := mkSource("shared")
+	reqs := mapper(source)
+	if len(reqs) != 1 || reqs[0].Namespace != "proj1" || reqs[0].Name != "sv1" {
+		t.Fatalf("Expected a request for proj1/sv1 but got %v", reqs)
+	}
+	source = mkSource("unused")
+	if reqs = mapper(source); len(reqs) != 0 {
+		t.Fatalf("Expected no requests but got %v", reqs)
+	}
+}
+
+// TestSharedVolumePolicy covers a SharedVolumePolicy being tightened so as to exclude an existing
+// SharedVolume, and loosened again.
+func TestSharedVolumePolicy(t *testing.T) {
+	r := fakeReconciler()
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "proj1", Labels: map[string]string{"team": "a"}}}
+	if err := r.client.Create(ctx, ns); err != nil {
+		t.Fatal(err)
+	}
+	p := &awsefsv1alpha1.SharedVolumePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+		Spec: awsefsv1alpha1.SharedVolumePolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Allowed:           []awsefsv1alpha1.AllowedVolume{{FileSystemID: "fs-123abc"}},
+		},
+	}
+	if err := r.client.Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	// Allowed
+	req := readySharedVolume(t, r)
+
+	// Restricted to a different access point
+	p.Spec.Allowed[0].AccessPointIDs = []string{"fsap-feedfacefeedface"}
+	if err := r.client.Update(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	expectFailure(t, r, req, reasonPolicyViolation, "SharedVolumePolicy team-a does not allow namespace proj1 "+
+		"to use file system fs-123abc with access point fsap-abc123abc123")
+	if _, pvMap, pvcMap := getResources(t, r.client); len(pvMap) != 1 || len(pvcMap) != 1 {
+		t.Fatalf("Expected the PV and PVC to be left alone but got\nPVs: %s\nPVCs: %s", pvMap, pvcMap)
+	}
+
+	// The namespace is no longer selected by the policy, so it's unrestricted.
+	ns.Labels = nil
+	if err := r.client.Update(ctx, ns); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ := validateResources(t, r.client, 1)
+	checkCondition(t, svMap["proj1/sv"], awsefsv1alpha1.SharedVolumeDegraded, metav1.ConditionFalse, reasonAsExpected)
+}
+
+func TestNamespaceToSharedVolumes(t *testing.T) {
+	r := fakeReconciler()
+	for _, nsname := range []types.NamespacedName{{Namespace: "proj1", Name: "sv1"}, {Namespace: "proj1", Name: "sv2"},
+		{Namespace: "proj2", Name: "sv1"}} {
+		sv := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Namespace: nsname.Namespace, Name: nsname.Name}}
+		if err := r.client.Create(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "proj1"}}
+	if reqs := namespaceToSharedVolumes(r.client)(ns); len(reqs) != 2 {
+		t.Fatalf("Expected requests for the two SharedVolumes in proj1 but got %v", reqs)
+	}
+}
+
+func TestPolicyToSharedVolumes(t *testing.T) {
+	r := fakeReconciler()
+	for _, nsname := range []types.NamespacedName{{Namespace: "proj1", Name: "sv1"}, {Namespace: "proj1", Name: "sv2"},
+		{Namespace: "proj2", Name: "sv1"}, {Namespace: "proj3", Name: "sv1"}} {
+		sv := &awsefsv1alpha1.SharedVolume{ObjectMeta: metav1.ObjectMeta{Namespace: nsname.Namespace, Name: nsname.Name}}
+		if err := r.client.Create(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, ns := range []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "proj1", Labels: map[string]string{"team": "a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "proj2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "proj3"}},
+	} {
+		if err := r.client.Create(ctx, ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mapper := policyToSharedVolumes(r.client)
+	names := func(reqs []reconcile.Request) []string {
+		names := []string{}
+		for _, req := range reqs {
+			names = append(names, req.String())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// Selected by label, and by name
+	p := &awsefsv1alpha1.SharedVolumePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p"},
+		Spec: awsefsv1alpha1.SharedVolumePolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Namespaces:        []string{"proj2"},
+		},
+	}
+	expected := []string{"proj1/sv1", "proj1/sv2", "proj2/sv1"}
+	if reqs := names(mapper(p)); !reflect.DeepEqual(reqs, expected) {
+		t.Fatalf("Expected requests for %v but got %v", expected, reqs)
+	}
+
+	// Selecting nothing
+	p.Spec = awsefsv1alpha1.SharedVolumePolicySpec{}
+	if reqs := mapper(p); len(reqs) != 0 {
+		t.Fatalf("Expected no requests but got %v", reqs)
+	}
+
+	// An invalid selector fails every SharedVolume
+	p.Spec.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "team", Operator: "bogus"},
+	}}
+	if reqs := mapper(p); len(reqs) != 4 {
+		t.Fatalf("Expected requests for all four SharedVolumes but got %v", reqs)
+	}
+}
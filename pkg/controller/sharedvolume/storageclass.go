@@ -0,0 +1,43 @@
+package sharedvolume
+
+// Helpers for SharedVolumes asking for a StorageClass other than the default.
+
+import (
+	"context"
+	"fmt"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/controller/statics"
+
+	"github.com/go-logr/logr"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkStorageClass checks that the StorageClass named by the `sharedVolume`'s
+// Spec.StorageClassName, if any, exists and belongs to the EFS CSI driver. The returned `reason`
+// and `message` are non-empty if it doesn't, in which case the caller should mark the SharedVolume
+// Failed.
+func (r *ReconcileSharedVolume) checkStorageClass(ctx context.Context, logger logr.Logger, sharedVolume *awsefsv1alpha1.SharedVolume) (
+	reason, message string, err error) {
+
+	scname := sharedVolume.Spec.StorageClassName
+	if scname == "" {
+		// The default is one of our statics.
+		return "", "", nil
+	}
+	sc := &storagev1.StorageClass{}
+	if err = r.client.Get(ctx, types.NamespacedName{Name: scname}, sc); err != nil {
+		if errors.IsNotFound(err) {
+			return reasonStorageClassNotFound, fmt.Sprintf("StorageClass %s does not exist", scname), nil
+		}
+		logger.Error(err, "Failed to retrieve StorageClass", "StorageClass", scname)
+		return "", "", err
+	}
+	if sc.Provisioner != statics.CSIDriverName {
+		return reasonStorageClassInvalid,
+			fmt.Sprintf("StorageClass %s is not for the %s driver", scname, statics.CSIDriverName), nil
+	}
+	return "", "", nil
+}
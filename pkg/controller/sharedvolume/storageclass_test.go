@@ -0,0 +1,121 @@
+package sharedvolume
+
+import (
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/controller/statics"
+	"openshift/aws-efs-operator/pkg/test"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestStorageClassName covers a SharedVolume asking for a StorageClass that doesn't exist, then
+// isn't the EFS CSI driver's, then is.
+func TestStorageClassName(t *testing.T) {
+	// Make sure the caches are cleared from other tests
+	pvBySharedVolume = newEnsurableCache()
+	pvcBySharedVolume = newEnsurableCache()
+
+	r := fakeReconciler()
+	sv := &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			AccessPointID:    "fsap-abc123abc123",
+			FileSystemID:     "fs-123abc",
+			StorageClassName: "efs-scratch",
+		},
+	}
+	if err := r.client.Create(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	req := makeRequest(t, sv)
+	// Finalizer, then Pending
+	for i := 0; i < 2; i++ {
+		if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+			t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+
+	// No such class yet
+	expectFailure(t, r, req, reasonStorageClassNotFound, "StorageClass efs-scratch does not exist")
+
+	// The class shows up, but it's for some other driver
+	sc := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "efs-scratch"},
+		Provisioner: "kubernetes.io/aws-ebs",
+	}
+	if err := r.client.Create(ctx, sc); err != nil {
+		t.Fatal(err)
+	}
+	expectFailure(t, r, req, reasonStorageClassInvalid, "StorageClass efs-scratch is not for the efs.csi.aws.com driver")
+
+	// Now it's right
+	if err := r.client.Delete(ctx, sc); err != nil {
+		t.Fatal(err)
+	}
+	sc = &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "efs-scratch"},
+		Provisioner: statics.CSIDriverName,
+	}
+	if err := r.client.Create(ctx, sc); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.NullResult || err != nil {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, pvMap, pvcMap := validateResources(t, r.client, 1)
+	pvName := pvNameForSharedVolume(svMap["proj1/sv"])
+	if scname := pvMap["/"+pvName].Spec.StorageClassName; scname != "efs-scratch" {
+		t.Fatalf("Expected the PV to have StorageClass efs-scratch but got %q", scname)
+	}
+	if scname := pvcMap["proj1/pvc-sv"].Spec.StorageClassName; scname == nil || *scname != "efs-scratch" {
+		t.Fatalf("Expected the PVC to have StorageClass efs-scratch but got %v", scname)
+	}
+
+	// Editing the class behind the webhook's back gets reverted
+	sv = svMap["proj1/sv"]
+	sv.Spec.StorageClassName = ""
+	if err := r.client.Update(ctx, sv); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := r.Reconcile(ctx, req); res != test.RequeueResult || err != nil {
+		t.Fatalf("Expected requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	svMap, _, _ = getResources(t, r.client)
+	if sv = svMap["proj1/sv"]; sv.Spec.StorageClassName != "efs-scratch" {
+		t.Fatalf("Expected storageClassName to be reverted but got %s", format(sv.Spec))
+	}
+}
+
+func TestStorageClassToSharedVolumes(t *testing.T) {
+	r := fakeReconciler()
+	for _, nsname := range []types.NamespacedName{{Namespace: "proj1", Name: "sv1"}, {Namespace: "proj2", Name: "sv2"}} {
+		sv := &awsefsv1alpha1.SharedVolume{
+			ObjectMeta: metav1.ObjectMeta{Namespace: nsname.Namespace, Name: nsname.Name},
+		}
+		if nsname.Name == "sv1" {
+			sv.Spec.StorageClassName = "efs-scratch"
+		}
+		if err := r.client.Create(ctx, sv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mapper := storageClassToSharedVolumes(r.client)
+
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "efs-scratch"}}
+	reqs := mapper(sc)
+	if len(reqs) != 1 || reqs[0].Namespace != "proj1" || reqs[0].Name != "sv1" {
+		t.Fatalf("Expected a request for proj1/sv1 but got %v", reqs)
+	}
+	// SharedVolumes using the default aren't mapped from it; it's one of our statics.
+	sc = &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: statics.StorageClassName}}
+	if reqs = mapper(sc); len(reqs) != 0 {
+		t.Fatalf("Expected no requests but got %v", reqs)
+	}
+}
@@ -3,8 +3,10 @@ package sharedvolume
 import (
 	"fmt"
 	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // This is used for PV and PVC definitions. Therein, it is required by schema and validated
@@ -13,5 +15,61 @@ import (
 var efsSize = resource.MustParse("1Gi")
 
 func svKey(sv *awsefsv1alpha1.SharedVolume) string {
-	return fmt.Sprintf("%s %s", sv.Namespace, sv.Name)
+	return nsnameKey(types.NamespacedName{Namespace: sv.Namespace, Name: sv.Name})
+}
+
+// nsnameKey is svKey for the SharedVolume with the NamespacedName `nsname`.
+func nsnameKey(nsname types.NamespacedName) string {
+	return fmt.Sprintf("%s %s", nsname.Namespace, nsname.Name)
+}
+
+// copyMap returns a copy of `m`, or nil if it's empty, so the caller can add to it without
+// affecting the original.
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// filterMap returns a copy of `m` without the keys for which `skip` is true, or nil if that leaves
+// nothing.
+func filterMap(m map[string]string, skip func(key string) bool) map[string]string {
+	var c map[string]string
+	for k, v := range m {
+		if skip(k) {
+			continue
+		}
+		if c == nil {
+			c = make(map[string]string, len(m))
+		}
+		c[k] = v
+	}
+	return c
+}
+
+// isOperatorLabel says whether the label `key` is one the operator sets for itself, rather than one
+// from a SharedVolume's ClaimLabels, which can't use the prefix.
+func isOperatorLabel(key string) bool {
+	return strings.HasPrefix(key, awsefsv1alpha1.ReservedLabelPrefix)
+}
+
+// isKubernetesAnnotation says whether the annotation `key` is in one of the domains Kubernetes
+// reserves, e.g. `pv.kubernetes.io/bound-by-controller`, which the PV controller sets.
+func isKubernetesAnnotation(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := key[:i]
+	for _, reserved := range []string{"kubernetes.io", "k8s.io"} {
+		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,301 @@
+package statics
+
+/**
+ * Overrides, from the OperatorConfig, to the definitions of the statics.
+ */
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/metrics"
+	"openshift/aws-efs-operator/pkg/util"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Names of the containers in daemonset.yaml and controller-deployment.yaml
+const (
+	driverContainer        = "efs-plugin"
+	registrarContainer     = "csi-driver-registrar"
+	livenessProbeContainer = "liveness-probe"
+	provisionerContainer   = "csi-provisioner"
+)
+
+// defaultArchitecture is the CPU architecture in the node selectors of daemonset.yaml and
+// controller-deployment.yaml.
+const defaultArchitecture = awsefsv1alpha1.ArchitectureAMD64
+
+// configNamespacedName identifies the singleton OperatorConfig.
+var configNamespacedName = types.NamespacedName{Name: awsefsv1alpha1.OperatorConfigName}
+
+// getConfig retrieves the OperatorConfig. It returns nil if there isn't one. (The OperatorConfig CRD
+// is installed with the operator, whose controller watches OperatorConfigs, so we can count on it.)
+func getConfig(ctx context.Context, log logr.Logger, client crclient.Client) (*awsefsv1alpha1.OperatorConfig, error) {
+	config := &awsefsv1alpha1.OperatorConfig{}
+	if err := client.Get(ctx, configNamespacedName, config); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to retrieve OperatorConfig.")
+		return nil, err
+	}
+	return config, nil
+}
+
+// applyConfig rebuilds the definitions of the statics from their templates and the overrides in
+// the OperatorConfig, which may be nil. The `region` is where the CSI driver's controller creates
+// access points, if dynamic provisioning is enabled. A definition is only replaced if it changed,
+// since doing so discards what its Ensurable has cached.
+func applyConfig(config *awsefsv1alpha1.OperatorConfig, region string) {
+	var spec *awsefsv1alpha1.OperatorConfigSpec
+	var dp *awsefsv1alpha1.DynamicProvisioning
+	if config != nil {
+		spec = &config.Spec
+		dp = spec.DynamicProvisioning
+	}
+	provisioningEnabled = dp != nil
+	controllerEnabled = spec != nil && needsController(spec)
+	replaceDefinition(daemonSetName, daemonSetDefinition(spec))
+	replaceDefinition(controllerDeploymentName, controllerDeploymentDefinition(spec, region))
+	replaceDefinition(provisioningStorageClassName, provisioningStorageClassDefinition(dp))
+	var classes []awsefsv1alpha1.StorageClassConfig
+	if spec != nil {
+		classes = spec.StorageClasses
+	}
+	applyStorageClasses(classes)
+}
+
+// needsController says whether the OperatorConfig `spec` calls for the CSI driver's controller,
+// i.e. whether any StorageClass provisions access points dynamically.
+func needsController(spec *awsefsv1alpha1.OperatorConfigSpec) bool {
+	if spec.DynamicProvisioning != nil {
+		return true
+	}
+	for _, c := range spec.StorageClasses {
+		if c.FileSystemID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyStorageClasses rebuilds the storageClassStatics, and their entries in the
+// staticResourceMap, from the StorageClasses in the OperatorConfig. The Ensurables of those that
+// were already listed are kept, so they don't lose what they cached.
+func applyStorageClasses(classes []awsefsv1alpha1.StorageClassConfig) {
+	previous := make(map[string]util.Ensurable, len(storageClassStatics))
+	for _, s := range storageClassStatics {
+		name := s.GetNamespacedName().Name
+		previous[name] = s
+		delete(staticResourceMap, name)
+	}
+	storageClassStatics = nil
+	for _, c := range classes {
+		if findStatic(types.NamespacedName{Name: c.Name}) != nil {
+			log.Info("Ignoring StorageClass in the OperatorConfig: the name is taken.", "name", c.Name)
+			continue
+		}
+		scDef := storageClassDefinition(c)
+		s, ok := previous[c.Name]
+		if !ok {
+			s = &util.EnsurableImpl{
+				ObjType:        &storagev1.StorageClass{},
+				NamespacedName: getNSName(scDef),
+				Definition:     scDef,
+				Apply:          true,
+				OnDrift:        countDrift("StorageClass"),
+				OnChange:       metrics.CountEnsureActions("StorageClass"),
+				// Its parameters can't be changed
+				Recreate: true,
+			}
+		} else if e := s.(*util.EnsurableImpl); !reflect.DeepEqual(scDef, e.Definition) {
+			e.SetDefinition(scDef)
+		}
+		storageClassStatics = append(storageClassStatics, s)
+		staticResourceMap[c.Name] = s
+	}
+}
+
+// replaceDefinition replaces the Definition of the static called `name` with `def`, if different.
+func replaceDefinition(name string, def crclient.Object) {
+	e := findStatic(types.NamespacedName{Name: name}).(*util.EnsurableImpl)
+	if !reflect.DeepEqual(def, e.Definition) {
+		e.SetDefinition(def)
+	}
+}
+
+// overrideContainers applies the image, pull policy and verbosity overrides in `spec` to the
+// containers in `podSpec`, along with the pull secrets. `images` maps container names to the images
+// they should use, if not empty.
+func overrideContainers(podSpec *corev1.PodSpec, spec *awsefsv1alpha1.OperatorConfigSpec, images map[string]string) {
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if image := images[c.Name]; image != "" {
+			c.Image = image
+		}
+		if spec.ImagePullPolicy != "" {
+			c.ImagePullPolicy = spec.ImagePullPolicy
+		}
+		if spec.LogLevel != nil {
+			for j, arg := range c.Args {
+				if strings.HasPrefix(arg, "--v=") {
+					c.Args[j] = fmt.Sprintf("--v=%d", *spec.LogLevel)
+				}
+			}
+		}
+	}
+	if len(spec.ImagePullSecrets) != 0 {
+		podSpec.ImagePullSecrets = spec.ImagePullSecrets
+	}
+}
+
+// daemonSetDefinition builds the DaemonSet from its template, with the overrides in `spec`, which
+// may be nil.
+func daemonSetDefinition(spec *awsefsv1alpha1.OperatorConfigSpec) *appsv1.DaemonSet {
+	dsDef := &appsv1.DaemonSet{}
+	loadDefTemplate(dsDef, "daemonset.yaml")
+	// DaemonSet is namespaced
+	dsDef.SetNamespace(namespaceName)
+	if spec == nil {
+		return dsDef
+	}
+
+	podSpec := &dsDef.Spec.Template.Spec
+	overrideContainers(podSpec, spec, map[string]string{
+		driverContainer:        spec.DriverImage,
+		registrarContainer:     spec.RegistrarImage,
+		livenessProbeContainer: spec.LivenessProbeImage,
+	})
+	if spec.NodeSelector != nil {
+		podSpec.NodeSelector = make(map[string]string, len(spec.NodeSelector)+1)
+		for k, v := range spec.NodeSelector {
+			podSpec.NodeSelector[k] = v
+		}
+	}
+	setArchitectures(podSpec, spec.Architectures)
+	if spec.Tolerations != nil {
+		podSpec.Tolerations = spec.Tolerations
+	}
+	if spec.PriorityClassName != "" {
+		podSpec.PriorityClassName = spec.PriorityClassName
+	}
+	return dsDef
+}
+
+// controllerDeploymentDefinition builds the CSI driver's controller Deployment from its template,
+// with the overrides in `spec`, which may be nil. The controller creates access points in the AWS
+// `region`, if not empty.
+func controllerDeploymentDefinition(spec *awsefsv1alpha1.OperatorConfigSpec, region string) *appsv1.Deployment {
+	deployDef := &appsv1.Deployment{}
+	loadDefTemplate(deployDef, "controller-deployment.yaml")
+	// Deployment is namespaced
+	deployDef.SetNamespace(namespaceName)
+	podSpec := &deployDef.Spec.Template.Spec
+	if region != "" {
+		for i := range podSpec.Containers {
+			if c := &podSpec.Containers[i]; c.Name == driverContainer {
+				c.Env = append(c.Env, corev1.EnvVar{Name: "AWS_REGION", Value: region})
+			}
+		}
+	}
+	if spec == nil {
+		return deployDef
+	}
+
+	// Placement overrides are for the DaemonSet, except that the images dictate the architectures.
+	images := map[string]string{livenessProbeContainer: spec.LivenessProbeImage}
+	if dp := spec.DynamicProvisioning; dp != nil {
+		images[driverContainer] = dp.ControllerImage
+		images[provisionerContainer] = dp.ProvisionerImage
+	}
+	overrideContainers(podSpec, spec, images)
+	setArchitectures(podSpec, spec.Architectures)
+	return deployDef
+}
+
+// provisioningStorageClassDefinition builds the StorageClass for dynamic provisioning from its
+// template and `dp`, which may be nil.
+func provisioningStorageClassDefinition(dp *awsefsv1alpha1.DynamicProvisioning) *storagev1.StorageClass {
+	scDef := &storagev1.StorageClass{}
+	loadDefTemplate(scDef, "provisioning-storageclass.yaml")
+	if dp == nil {
+		return scDef
+	}
+
+	scDef.Parameters["fileSystemId"] = dp.FileSystemID
+	if dp.DirectoryPerms != "" {
+		scDef.Parameters["directoryPerms"] = dp.DirectoryPerms
+	}
+	if dp.BasePath != "" {
+		scDef.Parameters["basePath"] = dp.BasePath
+	}
+	return scDef
+}
+
+// storageClassDefinition builds a StorageClass listed in the OperatorConfig: from the dynamic
+// provisioning template if it has a file system, otherwise from the template of the one used by
+// SharedVolumes.
+func storageClassDefinition(c awsefsv1alpha1.StorageClassConfig) *storagev1.StorageClass {
+	var scDef *storagev1.StorageClass
+	if c.FileSystemID != "" {
+		scDef = provisioningStorageClassDefinition(&awsefsv1alpha1.DynamicProvisioning{
+			FileSystemID:   c.FileSystemID,
+			DirectoryPerms: c.DirectoryPerms,
+			BasePath:       c.BasePath,
+		})
+	} else {
+		scDef = &storagev1.StorageClass{}
+		loadDefTemplate(scDef, "storageclass.yaml")
+	}
+	scDef.Name = c.Name
+	if c.ReclaimPolicy != nil {
+		policy := *c.ReclaimPolicy
+		scDef.ReclaimPolicy = &policy
+	}
+	scDef.MountOptions = c.MountOptions
+	return scDef
+}
+
+// setArchitectures restricts the pods to nodes of the given CPU `architectures`; by default, the
+// one in the template. A single architecture goes in the node selector, as in the template. More
+// than one needs node affinity.
+func setArchitectures(podSpec *corev1.PodSpec, architectures []awsefsv1alpha1.Architecture) {
+	if len(architectures) == 0 {
+		architectures = []awsefsv1alpha1.Architecture{defaultArchitecture}
+	}
+	delete(podSpec.NodeSelector, corev1.LabelArchStable)
+	if len(architectures) == 1 {
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = make(map[string]string)
+		}
+		podSpec.NodeSelector[corev1.LabelArchStable] = string(architectures[0])
+		return
+	}
+	values := make([]string, len(architectures))
+	for i, arch := range architectures {
+		values[i] = string(arch)
+	}
+	podSpec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      corev1.LabelArchStable,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   values,
+					}},
+				}},
+			},
+		},
+	}
+}
@@ -7,17 +7,24 @@ package statics
  */
 
 import (
+	"context"
 	"fmt"
+	"openshift/aws-efs-operator/pkg/efs"
+	"openshift/aws-efs-operator/pkg/metrics"
 	"openshift/aws-efs-operator/pkg/util"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
 	securityv1 "github.com/openshift/api/security/v1"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
@@ -40,8 +47,12 @@ var (
 	sccName            string
 	serviceAccountName string
 
+	controllerDeploymentName     string
+	provisioningStorageClassName string
+
 	// staticResources lists the resources the operator will create, and watch via the statics-controller.
-	// The order is significant: when bootstrapping, the operator will create the resources in this order.
+	// They're created in parallel, except for the DaemonSet, which waits for the rest (see
+	// EnsureStatics).
 	// This is populated by `initStatics()`.
 	staticResources []util.Ensurable
 
@@ -52,6 +63,25 @@ var (
 	// same name in different namespaces. But we really shouldn't do that.)
 	staticResourceMap = make(map[string]util.Ensurable)
 
+	// provisioningStatics lists the resources the operator creates only if the OperatorConfig calls
+	// for dynamic provisioning. Otherwise they're deleted, in reverse order. They're also in the
+	// staticResourceMap.
+	// This is populated by `initStatics()`.
+	provisioningStatics []util.Ensurable
+
+	// storageClassStatics lists the StorageClasses listed in the OperatorConfig. They're also in
+	// the staticResourceMap.
+	// This is populated by `applyConfig()`.
+	storageClassStatics []util.Ensurable
+
+	// controllerEnabled says whether the CSI driver's controller, i.e. the provisioningStatics
+	// other than the StorageClass, should exist. It's set by applyConfig.
+	controllerEnabled bool
+
+	// provisioningEnabled says whether the dynamic provisioning StorageClass should exist. It's
+	// set by applyConfig.
+	provisioningEnabled bool
+
 	// Global logger used for init()
 	glog = logf.Log.WithName("statics bootstrap")
 )
@@ -78,10 +108,8 @@ func init() {
 	sccDef.Users = append(sccDef.Users, saUser)
 	sccName = sccDef.Name
 
-	dsDef := &appsv1.DaemonSet{}
-	loadDefTemplate(dsDef, "daemonset.yaml")
-	// DaemonSet is namespaced
-	dsDef.SetNamespace(namespaceName)
+	// Overrides from the OperatorConfig get applied by EnsureStatics.
+	dsDef := daemonSetDefinition(nil)
 	daemonSetName = dsDef.Name
 
 	csiDef := &storagev1.CSIDriver{}
@@ -99,53 +127,166 @@ func init() {
 			NamespacedName: getNSName(saDef),
 			Definition:     saDef,
 			EqualFunc:      util.AlwaysEqual,
+			OnDrift:        countDrift("ServiceAccount"),
+			OnChange:       metrics.CountEnsureActions("ServiceAccount"),
 		},
 		&util.EnsurableImpl{
 			ObjType:        &securityv1.SecurityContextConstraints{},
 			NamespacedName: getNSName(sccDef),
 			Definition:     sccDef,
-			// SCC has no Spec; the meat is at the top level
-			EqualFunc: util.EqualOtherThanMeta,
+			Apply:          true,
+			OnDrift:        countDrift("SecurityContextConstraints"),
+			OnChange:       metrics.CountEnsureActions("SecurityContextConstraints"),
 		},
 		&util.EnsurableImpl{
 			ObjType:        &appsv1.DaemonSet{},
 			NamespacedName: getNSName(dsDef),
 			Definition:     dsDef,
-			EqualFunc:      daemonSetEqual,
+			// The server and admission plugins fill in much of the spec
+			Apply:    true,
+			OnDrift:  countDrift("DaemonSet"),
+			OnChange: metrics.CountEnsureActions("DaemonSet"),
 		},
 		&util.EnsurableImpl{
 			ObjType:        &storagev1.CSIDriver{},
 			NamespacedName: getNSName(csiDef),
 			Definition:     csiDef,
-			EqualFunc:      csiDriverEqual,
+			// Own only the fields we set, leaving those the server defaults alone
+			Apply:    true,
+			OnDrift:  countDrift("CSIDriver"),
+			OnChange: metrics.CountEnsureActions("CSIDriver"),
+			// Its spec can't be changed
+			Recreate: true,
 		},
 		&util.EnsurableImpl{
 			ObjType:        &storagev1.StorageClass{},
 			NamespacedName: getNSName(scDef),
 			Definition:     scDef,
-			// StorageClass has no Spec; the meat is at the top level
-			EqualFunc: util.EqualOtherThanMeta,
+			Apply:          true,
+			OnDrift:        countDrift("StorageClass"),
+			OnChange:       metrics.CountEnsureActions("StorageClass"),
+		},
+	}
+
+	// The CSI driver's controller, for dynamic provisioning
+	controllerSADef := &corev1.ServiceAccount{}
+	loadDefTemplate(controllerSADef, "controller-serviceaccount.yaml")
+	controllerSADef.SetNamespace(namespaceName)
+
+	crDef := &rbacv1.ClusterRole{}
+	loadDefTemplate(crDef, "controller-clusterrole.yaml")
+
+	crbDef := &rbacv1.ClusterRoleBinding{}
+	loadDefTemplate(crbDef, "controller-clusterrolebinding.yaml")
+	// Bind the controller's service account
+	crbDef.Subjects[0].Namespace = namespaceName
+
+	// Overrides from the OperatorConfig get applied by EnsureStatics.
+	deployDef := controllerDeploymentDefinition(nil, "")
+	controllerDeploymentName = deployDef.Name
+
+	provSCDef := provisioningStorageClassDefinition(nil)
+	provisioningStorageClassName = provSCDef.Name
+
+	provisioningStatics = []util.Ensurable{
+		&util.EnsurableImpl{
+			ObjType:        &corev1.ServiceAccount{},
+			NamespacedName: getNSName(controllerSADef),
+			Definition:     controllerSADef,
+			EqualFunc:      util.AlwaysEqual,
+			OnDrift:        countDrift("ServiceAccount"),
+			OnChange:       metrics.CountEnsureActions("ServiceAccount"),
+		},
+		&util.EnsurableImpl{
+			ObjType:        &rbacv1.ClusterRole{},
+			NamespacedName: getNSName(crDef),
+			Definition:     crDef,
+			Apply:          true,
+			OnDrift:        countDrift("ClusterRole"),
+			OnChange:       metrics.CountEnsureActions("ClusterRole"),
+		},
+		&util.EnsurableImpl{
+			ObjType:        &rbacv1.ClusterRoleBinding{},
+			NamespacedName: getNSName(crbDef),
+			Definition:     crbDef,
+			Apply:          true,
+			OnDrift:        countDrift("ClusterRoleBinding"),
+			OnChange:       metrics.CountEnsureActions("ClusterRoleBinding"),
+		},
+		&util.EnsurableImpl{
+			ObjType:        &appsv1.Deployment{},
+			NamespacedName: getNSName(deployDef),
+			Definition:     deployDef,
+			// See the DaemonSet
+			Apply:    true,
+			OnDrift:  countDrift("Deployment"),
+			OnChange: metrics.CountEnsureActions("Deployment"),
+		},
+		&util.EnsurableImpl{
+			ObjType:        &storagev1.StorageClass{},
+			NamespacedName: getNSName(provSCDef),
+			Definition:     provSCDef,
+			Apply:          true,
+			OnDrift:        countDrift("StorageClass"),
+			OnChange:       metrics.CountEnsureActions("StorageClass"),
+			// Its parameters can't be changed
+			Recreate: true,
 		},
 	}
 
 	// Populate our lookup map
-	for _, s := range staticResources {
+	for _, s := range allStatics() {
 		staticResourceMap[s.GetNamespacedName().Name] = s
 	}
 }
 
+// allStatics returns all the statics, whether or not they should exist.
+func allStatics() []util.Ensurable {
+	all := append([]util.Ensurable{}, staticResources...)
+	all = append(all, provisioningStatics...)
+	return append(all, storageClassStatics...)
+}
+
+// activeStatics returns the statics that should exist, according to the OperatorConfig.
+func activeStatics() []util.Ensurable {
+	active := append([]util.Ensurable{}, staticResources...)
+	if controllerEnabled {
+		for _, s := range provisioningStatics {
+			// The controller also serves the StorageClasses in the OperatorConfig, so it may be
+			// needed without its own StorageClass.
+			if s.GetNamespacedName().Name != provisioningStorageClassName || provisioningEnabled {
+				active = append(active, s)
+			}
+		}
+	}
+	return append(active, storageClassStatics...)
+}
+
+// isActive returns whether the static `s` should exist, according to the OperatorConfig.
+func isActive(s util.Ensurable) bool {
+	for _, a := range activeStatics() {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
+
+// countDrift returns an OnDrift hook counting restorations of the static of the given `kind`.
+func countDrift(kind string) func() {
+	return func() {
+		metrics.StaticsDriftCorrections.WithLabelValues(kind).Inc()
+	}
+}
+
 func loadDefTemplate(receiver runtime.Object, defFile string) {
 	if err := yaml.Unmarshal(MustAsset(filepath.Join("defs", defFile)), receiver); err != nil {
 		panic(fmt.Sprintf("Couldn't load %s: %s", defFile, err.Error()))
 	}
 }
 
-func getNSName(definition runtime.Object) types.NamespacedName {
-	nsname, err := crclient.ObjectKeyFromObject(definition)
-	if err != nil {
-		panic(fmt.Sprintf("Couldn't extract NamespacedName from definition: %s", err.Error()))
-	}
-	return nsname
+func getNSName(definition crclient.Object) types.NamespacedName {
+	return crclient.ObjectKeyFromObject(definition)
 }
 
 // discoverNamespace discovers the namespace we're running in and sets the global `namespaceName`
@@ -193,31 +334,129 @@ func findStatic(nsname types.NamespacedName) util.Ensurable {
 	return nil
 }
 
-// EnsureStatics creates and/or updates all the staticResources
-func EnsureStatics(log logr.Logger, client crclient.Client) error {
-	errcount := 0
-	for _, s := range staticResources {
-		if err := s.Ensure(log, client); err != nil {
-			// Ensure already logged, just keep track of how many errors we saw
-			errcount++
+// EnsureError is returned by EnsureStatics when some of the statics couldn't be ensured (or
+// deleted).
+type EnsureError struct {
+	// Failed lists the statics, as `Kind/name`.
+	Failed []string
+}
+
+// Error implements error.
+func (e *EnsureError) Error() string {
+	return fmt.Sprintf("Encountered %d error(s) ensuring statics: %s", len(e.Failed), strings.Join(e.Failed, ", "))
+}
+
+// EnsureStatics creates and/or updates all the statics, according to the OperatorConfig. Statics
+// the OperatorConfig doesn't call for are deleted. If it gets as far as the statics themselves,
+// any error is an *EnsureError saying which failed.
+func EnsureStatics(ctx context.Context, log logr.Logger, client crclient.Client) error {
+	config, err := getConfig(ctx, log, client)
+	if err != nil {
+		return err
+	}
+	region := ""
+	if config != nil && needsController(&config.Spec) {
+		// The CSI driver's controller needs to know where to create access points.
+		if region, err = efs.DiscoverRegion(ctx, client); err != nil {
+			log.Error(err, "Couldn't discover AWS region.")
+			return err
+		}
+	}
+	applyConfig(config, region)
+
+	// Ensure the statics in parallel, so one that's unavailable (e.g. the SecurityContextConstraints
+	// API) neither holds up nor fails the others. Those that fail are retried with the whole pass,
+	// which the statics controller requeues with its rate limiter's backoff. The workloads go last:
+	// their pods are rejected unless their ServiceAccount (and, for the DaemonSet, the
+	// SecurityContextConstraints) exists, and the DaemonSet and Deployment controllers only retry
+	// them with backoff. So if anything else failed, they wait for the next pass.
+	var prerequisites, workloads []util.Ensurable
+	for _, s := range activeStatics() {
+		if isWorkload(s) {
+			workloads = append(workloads, s)
+		} else {
+			prerequisites = append(prerequisites, s)
 		}
 	}
-	if errcount != 0 {
-		return fmt.Errorf("Encountered %d error(s) ensuring statics", errcount)
+	failed := ensureAll(ctx, log, client, prerequisites)
+	if len(failed) == 0 {
+		failed = ensureAll(ctx, log, client, workloads)
+	}
+
+	// In reverse order, so the controller goes before its permissions
+	for i := len(provisioningStatics) - 1; i >= 0; i-- {
+		if s := provisioningStatics[i]; !isActive(s) {
+			// Delete already logged
+			if err := s.Delete(ctx, log, client); err != nil {
+				failed = append(failed, staticName(s))
+			}
+		}
+	}
+	failed = append(failed, deleteStaleStorageClasses(ctx, log, client)...)
+	if len(failed) != 0 {
+		return &EnsureError{Failed: failed}
 	}
 	return nil
 }
 
-func csiDriverEqual(local, server runtime.Object) bool {
-	return reflect.DeepEqual(
-		local.(*storagev1.CSIDriver).Spec,
-		server.(*storagev1.CSIDriver).Spec,
-	)
+// ensureAll ensures the `statics` in parallel, returning those that failed, as `Kind/name`.
+func ensureAll(ctx context.Context, log logr.Logger, client crclient.Client, statics []util.Ensurable) []string {
+	errs := make([]error, len(statics))
+	var wg sync.WaitGroup
+	for i, s := range statics {
+		wg.Add(1)
+		go func(i int, s util.Ensurable) {
+			defer wg.Done()
+			// Ensure already logged
+			errs[i] = s.Ensure(ctx, log, client)
+		}(i, s)
+	}
+	wg.Wait()
+	failed := []string{}
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, staticName(statics[i]))
+		}
+	}
+	return failed
+}
+
+// isWorkload says whether the static `s` runs pods, i.e. is the DaemonSet or the controller
+// Deployment.
+func isWorkload(s util.Ensurable) bool {
+	switch s.GetType().(type) {
+	case *appsv1.DaemonSet, *appsv1.Deployment:
+		return true
+	}
+	return false
+}
+
+// staticName identifies the static `s` in messages, as `Kind/name`.
+func staticName(s util.Ensurable) string {
+	return fmt.Sprintf("%s/%s", reflect.TypeOf(s.GetType()).Elem().Name(), s.GetNamespacedName().Name)
 }
 
-func daemonSetEqual(local, server runtime.Object) bool {
-	// TODO: k8s updates fields in the Spec :(
-	return reflect.DeepEqual(
-		local.(*appsv1.DaemonSet).Spec,
-		server.(*appsv1.DaemonSet).Spec)
+// deleteStaleStorageClasses deletes StorageClasses we created for the OperatorConfig that it no
+// longer lists, including any removed while the operator wasn't running. It returns those it
+// failed to delete, as `StorageClass/name`, or `StorageClass/*` if it couldn't list them.
+func deleteStaleStorageClasses(ctx context.Context, log logr.Logger, client crclient.Client) []string {
+	scList := &storagev1.StorageClassList{}
+	if err := client.List(ctx, scList, util.ICareSelector()); err != nil {
+		log.Error(err, "Failed to list StorageClasses.")
+		return []string{"StorageClass/*"}
+	}
+	failed := []string{}
+	for i := range scList.Items {
+		sc := &scList.Items[i]
+		if findStatic(types.NamespacedName{Name: sc.Name}) != nil {
+			// Still managed, or taken care of above
+			continue
+		}
+		log.Info("Deleting StorageClass no longer in the OperatorConfig.", "resource", sc.Name)
+		if err := client.Delete(ctx, sc); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete.", "resource", sc.Name)
+			failed = append(failed, "StorageClass/"+sc.Name)
+		}
+	}
+	return failed
 }
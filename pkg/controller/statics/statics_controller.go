@@ -8,26 +8,39 @@ This controller knows how to bootstrap these objects and watch them for changes
 
 import (
 	"context"
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/health"
+	"openshift/aws-efs-operator/pkg/metrics"
 	"openshift/aws-efs-operator/pkg/util"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-const svCRDName = "sharedvolumes.aws-efs.managed.openshift.io"
+const (
+	svCRDName      = "sharedvolumes.aws-efs.managed.openshift.io"
+	controllerName = "statics-controller"
+)
 
 var log = logf.Log.WithName("controller_statics")
 
@@ -39,26 +52,48 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileStatics{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileStatics{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
-	// Create a new controller
-	c, err := controller.New("statics-controller", mgr, controller.Options{Reconciler: r})
-	if err != nil {
-		return err
-	}
-
-	// Add watches for each of our static resources.
-	for _, t := range staticResources {
-		err = c.Watch(&source.Kind{Type: t.GetType()}, &handler.EnqueueRequestForObject{}, util.ICarePredicate)
-		if err != nil {
-			return err
+	// The OperatorConfig is the primary resource, since its overrides change the statics'
+	// definitions. Only spec changes matter, and only to the one named `cluster`.
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&awsefsv1alpha1.OperatorConfig{}, builder.WithPredicates(
+			predicate.GenerationChangedPredicate{},
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == awsefsv1alpha1.OperatorConfigName
+			})))
+
+	// Add watches for each type of our static resources, including those that only exist if the
+	// OperatorConfig calls for them. The statics are cluster-scoped or live in other namespaces, so
+	// they can't be owned by the OperatorConfig; each one reconciles itself.
+	watched := make(map[reflect.Type]bool)
+	for _, t := range allStatics() {
+		objType := reflect.TypeOf(t.GetType())
+		if watched[objType] {
+			continue
 		}
+		watched[objType] = true
+		b = b.Watches(&source.Kind{Type: t.GetType()}, &handler.EnqueueRequestForObject{},
+			builder.WithPredicates(util.ICarePredicate))
 	}
 
-	return nil
+	// Ask for a pass over all the statics as soon as the controller starts, as if the
+	// OperatorConfig had changed, since there may be no OperatorConfig (or statics) to trigger one.
+	bootstrap := make(chan event.GenericEvent, 1)
+	bootstrap <- event.GenericEvent{Object: &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+	}}
+	b = b.Watches(&source.Channel{Source: bootstrap}, &handler.EnqueueRequestForObject{})
+
+	return b.Complete(r)
 }
 
 // blank assignment to verify that ReconcileStatics implements reconcile.Reconciler
@@ -68,16 +103,23 @@ var _ reconcile.Reconciler = &ReconcileStatics{}
 type ReconcileStatics struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	// configured says whether a pass over all the statics has applied the OperatorConfig to their
+	// definitions. (Only one reconcile runs at a time, so this needs no locking.)
+	configured bool
 }
 
 // Reconcile reads that state of the cluster for static objects and makes changes based on the state read
 // Note:
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-func (r *ReconcileStatics) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileStatics) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	defer metrics.ObserveReconcile(controllerName, time.Now())
+	ctx, cancel := util.ReconcileContext(ctx)
+	defer cancel()
 
 	var (
 		crd *apiextensions.CustomResourceDefinition
@@ -85,11 +127,13 @@ func (r *ReconcileStatics) Reconcile(request reconcile.Request) (reconcile.Resul
 	)
 
 	// We got this far, so it's a type we're watching that also passed our filter. That means it
-	// ought to be one of our statics.
+	// ought to be one of our statics, or the OperatorConfig.
+	isConfig := request.NamespacedName == configNamespacedName
 	s := findStatic(request.NamespacedName)
-	if s == nil {
-		// This should really never happen.
-		reqLogger.Error(nil, "Got an unexpected reconcile request.", "request", request)
+	if s == nil && !isConfig {
+		// This should really never happen, except for a StorageClass just removed from the
+		// OperatorConfig, which EnsureStatics deletes.
+		reqLogger.Info("Got a reconcile request for a resource we don't manage.", "request", request)
 		// Don't requeue this one, either explicitly (Requeue=true) or implicitly (by returning an error)
 		return reconcile.Result{}, nil
 	}
@@ -99,10 +143,10 @@ func (r *ReconcileStatics) Reconcile(request reconcile.Request) (reconcile.Resul
 	// TODO(efried): Except for the SCC, which for some reason seems to ignore the OwnerReferences
 	// and not get deleted. This may be an upstream bug.
 	// See https://github.com/openshift/aws-efs-operator/issues/23
-	if crd, err = discoverCRD(r.client); err != nil {
+	if crd, err = discoverCRD(ctx, r.client); err != nil {
 		if errors.IsNotFound(err) {
 			// TODO(efried): Delete when https://github.com/openshift/aws-efs-operator/issues/23 is resolved.
-			deleteSCC(reqLogger, r.client)
+			deleteSCC(ctx, reqLogger, r.client)
 			reqLogger.Info("SharedVolume CRD has already been deleted. Skipping reconcile, awaiting demise.")
 			return reconcile.Result{}, nil
 		}
@@ -115,30 +159,76 @@ func (r *ReconcileStatics) Reconcile(request reconcile.Request) (reconcile.Resul
 	// restore below).
 	if crd.GetDeletionTimestamp() != nil {
 		// TODO(efried): Delete when https://github.com/openshift/aws-efs-operator/issues/23 is resolved.
-		deleteSCC(reqLogger, r.client)
+		deleteSCC(ctx, reqLogger, r.client)
 		reqLogger.Info("The SharedVolume CRD is being deleted, which means we're shutting down. Skipping reconcile.")
 		return reconcile.Result{}, nil
 	}
 	reqLogger.Info("Reconciling.", "request", request)
 
+	// Until the OperatorConfig has been applied, the definitions are the defaults, and ensuring a
+	// single static with those could undo its overrides. So the first request, whatever it is for,
+	// gets a pass over all the statics.
+	if !r.configured {
+		isConfig = true
+	}
+
+	if isConfig {
+		// The OperatorConfig changed (or appeared, or went away): bring all the statics in line.
+		for _, s := range allStatics() {
+			s.SetOwner(util.AsOwner(crd))
+			s.SetEventRecorder(r.recorder, nil)
+		}
+		err = EnsureStatics(ctx, reqLogger, r.client)
+		if _, ok := err.(*EnsureError); err == nil || ok {
+			// Got as far as applying the OperatorConfig, even if some statics failed
+			r.configured = true
+		}
+		reportStatus(ctx, reqLogger, r.client, r.recorder, crd, err)
+		if err != nil {
+			// We'll keep trying, with the controller's backoff, until they're all in place. The
+			// error alone requeues us through the rate limiter.
+			return reconcile.Result{}, err
+		}
+		Ensured.Set()
+		return reconcile.Result{}, nil
+	}
+
+	if !isActive(s) {
+		// E.g. the dynamic provisioning controller, which EnsureStatics deleted because the
+		// OperatorConfig no longer calls for it. Leave it gone.
+		reqLogger.Info("Static is disabled by the OperatorConfig. Not restoring.")
+		return reconcile.Result{}, nil
+	}
+
 	// Make sure the static is "owned" by the CRD.
 	// We need to do this here because we can't count on the CRD existing during static setup.
 	s.SetOwner(util.AsOwner(crd))
+	// Record Events (e.g. when we have to restore the static) against the static itself.
+	s.SetEventRecorder(r.recorder, nil)
 
-	if err := s.Ensure(reqLogger, r.client); err != nil {
+	if err := s.Ensure(ctx, reqLogger, r.client); err != nil {
 		// TODO: Max retries so we don't get in a hard loop when the failure is something incurable?
 		return reconcile.Result{Requeue: true}, err
 	}
 	return reconcile.Result{}, nil
 }
 
+// CRDDiscovered returns a readiness check that passes once our SharedVolume
+// CustomResourceDefinition has been found via `reader`.
+func CRDDiscovered(reader crclient.Reader) healthz.Checker {
+	return health.Once(func(ctx context.Context) error {
+		_, err := discoverCRD(ctx, reader)
+		return err
+	})
+}
+
 // discoverCRD finds our SharedVolume CustomResourceDefinition.
-func discoverCRD(client crclient.Client) (*apiextensions.CustomResourceDefinition, error) {
+func discoverCRD(ctx context.Context, client crclient.Reader) (*apiextensions.CustomResourceDefinition, error) {
 	crd := &apiextensions.CustomResourceDefinition{}
 	nsn := types.NamespacedName{
 		Name: svCRDName,
 	}
-	if err := client.Get(context.TODO(), nsn, crd); err != nil {
+	if err := client.Get(ctx, nsn, crd); err != nil {
 		return nil, err
 	}
 	return crd, nil
@@ -147,9 +237,9 @@ func discoverCRD(client crclient.Client) (*apiextensions.CustomResourceDefinitio
 // deleteSCC deletes the SecurityContextConstraints static.
 // TODO(efried): This is a *workaround* for https://github.com/openshift/aws-efs-operator/issues/23
 // It should be deleted when that issue is resolved (upstream, or here in some better way).
-func deleteSCC(logger logr.Logger, client crclient.Client) {
+func deleteSCC(ctx context.Context, logger logr.Logger, client crclient.Client) {
 	logger.Info("Manually deleting SecurityContextConstraints. See https://github.com/openshift/aws-efs-operator/issues/23")
 	scce := findStatic(types.NamespacedName{Name: sccName})
 	// Delete() does the logging. We're ignoring any errors.
-	_ = scce.Delete(logger, client)
+	_ = scce.Delete(ctx, logger, client)
 }
@@ -2,24 +2,35 @@ package statics
 
 import (
 	"context"
+	"fmt"
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
 	"openshift/aws-efs-operator/pkg/fixtures"
+	"openshift/aws-efs-operator/pkg/metrics"
 	"openshift/aws-efs-operator/pkg/test"
 	"openshift/aws-efs-operator/pkg/util"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
 	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	// TODO: pkg/client/fake is deprecated, replace with pkg/envtest
 	"sigs.k8s.io/controller-runtime/pkg/client/fake" //nolint:staticcheck
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -28,14 +39,19 @@ import (
 
 // TODO: Test add()/watches somehow?
 
+// setup returns a reconciler that is past its first pass over the statics.
 func setup() (logr.Logger, *ReconcileStatics) {
 
 	// OpenShift types need to be registered explicitly
 	scheme.Scheme.AddKnownTypes(securityv1.SchemeGroupVersion, &securityv1.SecurityContextConstraints{})
 	// And so do extensions
 	scheme.Scheme.AddKnownTypes(apiextensions.SchemeGroupVersion, &apiextensions.CustomResourceDefinition{})
+	scheme.Scheme.AddKnownTypes(configv1.GroupVersion, &configv1.Infrastructure{})
+	// And so do ours
+	scheme.Scheme.AddKnownTypes(awsefsv1alpha1.SchemeGroupVersion, &awsefsv1alpha1.OperatorConfig{})
 
-	client := fake.NewFakeClientWithScheme(scheme.Scheme)
+	// The CSIDriver is applied, which the fake client can't do alone
+	client := &test.ApplyingClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
 
 	err := client.Create(context.TODO(), &apiextensions.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
@@ -46,7 +62,7 @@ func setup() (logr.Logger, *ReconcileStatics) {
 		panic(err)
 	}
 
-	return logf.Log.Logger, &ReconcileStatics{client: client, scheme: scheme.Scheme}
+	return logf.Log.Logger, &ReconcileStatics{client: client, scheme: scheme.Scheme, recorder: test.NewFakeRecorder(), configured: true}
 }
 
 // TestStartup simulates operator startup by creating the statics before the CRD is discovered,
@@ -56,8 +72,8 @@ func TestStartup(t *testing.T) {
 
 	logger, r := setup()
 
-	// This is how statics are bootstrapped on operator startup
-	if err := EnsureStatics(logger, r.client); err != nil {
+	// E.g. statics created before the CRD existed
+	if err := EnsureStatics(context.TODO(), logger, r.client); err != nil {
 		t.Fatal(err)
 	}
 
@@ -73,7 +89,7 @@ func TestStartup(t *testing.T) {
 			t.Fatalf("Expected no OwnerReferences but got %v", orefs)
 		}
 		// Until we reconcile
-		res, err := r.Reconcile(reconcile.Request{NamespacedName: nsname})
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsname})
 		if err != nil {
 			t.Fatalf("Didn't expect an error, but got %v", err)
 		}
@@ -115,7 +131,7 @@ func TestReconcile(t *testing.T) {
 	// We'll use these later
 	var (
 		dsStatic  util.Ensurable
-		resources map[string]runtime.Object
+		resources map[string]crclient.Object
 	)
 
 	// Now let's run the reconciler for each of our tracked resources.
@@ -126,7 +142,7 @@ func TestReconcile(t *testing.T) {
 			dsStatic = staticResource
 		}
 		logger.Info("Bootstrap: reconciling", "resource", nsname)
-		res, err := r.Reconcile(reconcile.Request{NamespacedName: nsname})
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsname})
 		if err != nil {
 			t.Fatalf("Didn't expect an error, but got %v", err)
 		}
@@ -146,13 +162,24 @@ func TestReconcile(t *testing.T) {
 	}
 
 	// Now reconcile it
-	res, err := r.Reconcile(reconcile.Request{NamespacedName: dsStatic.GetNamespacedName()})
+	test.DrainEvents(r.recorder.(*record.FakeRecorder))
+	driftsBefore := testutil.ToFloat64(metrics.StaticsDriftCorrections.WithLabelValues("DaemonSet"))
+	res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: dsStatic.GetNamespacedName()})
 	if err != nil {
 		t.Fatalf("Didn't expect an error, but got %v", err)
 	}
 	if !reflect.DeepEqual(res, test.NullResult) {
 		t.Fatalf("Unexpected result.\nExpected: %v\nGot:     %v", test.NullResult, res)
 	}
+	// The restoration shows up as an Event
+	expectEvent := fmt.Sprintf("Normal Updated Updated DaemonSet %s", daemonSetName)
+	if events := test.DrainEvents(r.recorder.(*record.FakeRecorder)); !reflect.DeepEqual(events, []string{expectEvent}) {
+		t.Fatalf("Expected Event %q but got %v", expectEvent, events)
+	}
+	// ...and is counted as drift
+	if drifts := testutil.ToFloat64(metrics.StaticsDriftCorrections.WithLabelValues("DaemonSet")) - driftsBefore; drifts != 1 {
+		t.Fatalf("Expected 1 DaemonSet drift correction but got %v", drifts)
+	}
 
 	// And now it should be golden again. Check all the things, to make sure we didn't do something bad to them.
 	checkStatics(t, r.client)
@@ -184,7 +211,7 @@ func TestReconcileCRDVariants(t *testing.T) {
 		}
 		for _, staticResource := range staticResources {
 			nsname := staticResource.GetNamespacedName()
-			if _, err := r.Reconcile(reconcile.Request{NamespacedName: nsname}); err != nil {
+			if _, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsname}); err != nil {
 				t.Fatal(err)
 			}
 
@@ -218,7 +245,7 @@ func TestReconcileCRDVariants(t *testing.T) {
 
 	// Setup
 	reset()
-	if crd, err = discoverCRD(r.client); err != nil {
+	if crd, err = discoverCRD(context.TODO(), r.client); err != nil {
 		t.Fatal(err)
 	}
 
@@ -232,7 +259,7 @@ func TestReconcileCRDVariants(t *testing.T) {
 	// Overkill, but prove this behaves the same for any static
 	for _, staticResource := range staticResources {
 		nsname := staticResource.GetNamespacedName()
-		res, err := r.Reconcile(reconcile.Request{NamespacedName: nsname})
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsname})
 		if err != nil {
 			t.Fatalf("Expected no error reconciling %v but got %v", nsname, err)
 		}
@@ -253,7 +280,7 @@ func TestReconcileCRDVariants(t *testing.T) {
 	// Same again
 	for _, staticResource := range staticResources {
 		nsname := staticResource.GetNamespacedName()
-		res, err := r.Reconcile(reconcile.Request{NamespacedName: nsname})
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsname})
 		if err != nil {
 			t.Fatalf("Expected no error reconciling %v but got %v", nsname, err)
 		}
@@ -277,7 +304,7 @@ func TestReconcileCRDVariants(t *testing.T) {
 		// Set our fake to error on this reconcile.
 		fcwce.GetBehavior[i] = fixtures.AlreadyExists
 		nsname := staticResource.GetNamespacedName()
-		res, err := r.Reconcile(reconcile.Request{NamespacedName: nsname})
+		res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsname})
 		if err != nil {
 			t.Fatalf("Expected no error reconciling %v but got %v", nsname, err)
 		}
@@ -289,11 +316,370 @@ func TestReconcileCRDVariants(t *testing.T) {
 	check(true)
 }
 
+// TestReconcileConfig covers creating, changing and deleting the OperatorConfig.
+func TestReconcileConfig(t *testing.T) {
+	ctx := context.TODO()
+	logger, r := setup()
+	if err := EnsureStatics(context.TODO(), logger, r.client); err != nil {
+		t.Fatal(err)
+	}
+	req := reconcile.Request{NamespacedName: configNamespacedName}
+	reconcileAndCheck := func(expectedImage string) {
+		t.Helper()
+		if res, err := r.Reconcile(context.TODO(), req); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+			t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+		ds := &appsv1.DaemonSet{}
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: daemonSetName}, ds); err != nil {
+			t.Fatal(err)
+		}
+		if image := ds.Spec.Template.Spec.Containers[0].Image; image != expectedImage {
+			t.Fatalf("Expected driver image %q but got %q", expectedImage, image)
+		}
+		if orefs := ds.GetOwnerReferences(); len(orefs) != 1 || orefs[0].Name != svCRDName {
+			t.Fatalf("Expected the DaemonSet to be owned by the CRD but got %v", orefs)
+		}
+	}
+	defaultImage := daemonSetDefinition(nil).Spec.Template.Spec.Containers[0].Image
+
+	config := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+		Spec:       awsefsv1alpha1.OperatorConfigSpec{DriverImage: "mirror.example.com/efs-csi-driver:v1"},
+	}
+	if err := r.client.Create(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileAndCheck("mirror.example.com/efs-csi-driver:v1")
+
+	refreshConfig(t, r.client, config)
+	config.Spec.DriverImage = "mirror.example.com/efs-csi-driver:v2"
+	if err := r.client.Update(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileAndCheck("mirror.example.com/efs-csi-driver:v2")
+
+	// Statics reconciles keep the override
+	dsReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespaceName, Name: daemonSetName}}
+	if _, err := r.Reconcile(context.TODO(), dsReq); err != nil {
+		t.Fatal(err)
+	}
+	reconcileAndCheck("mirror.example.com/efs-csi-driver:v2")
+
+	// Back to the defaults
+	if err := r.client.Delete(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileAndCheck(defaultImage)
+}
+
+// TestReconcileConfigRemoved makes sure that overrides removed from the OperatorConfig are removed
+// from the DaemonSet, rather than taken for server defaults.
+func TestReconcileConfigRemoved(t *testing.T) {
+	ctx := context.TODO()
+	logger, r := setup()
+	if err := EnsureStatics(context.TODO(), logger, r.client); err != nil {
+		t.Fatal(err)
+	}
+	req := reconcile.Request{NamespacedName: configNamespacedName}
+	reconcileAndGet := func() *corev1.PodSpec {
+		t.Helper()
+		if res, err := r.Reconcile(context.TODO(), req); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+			t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+		ds := &appsv1.DaemonSet{}
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: daemonSetName}, ds); err != nil {
+			t.Fatal(err)
+		}
+		return &ds.Spec.Template.Spec
+	}
+
+	pullSecrets := []corev1.LocalObjectReference{{Name: "mirror-pull-secret"}}
+	config := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+		Spec: awsefsv1alpha1.OperatorConfigSpec{
+			PriorityClassName: "system-node-critical",
+			ImagePullSecrets:  pullSecrets,
+		},
+	}
+	if err := r.client.Create(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	podSpec := reconcileAndGet()
+	if podSpec.PriorityClassName != "system-node-critical" || !reflect.DeepEqual(podSpec.ImagePullSecrets, pullSecrets) {
+		t.Fatalf("Expected the overrides to be applied but got %v", podSpec)
+	}
+
+	if err := r.client.Delete(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	podSpec = reconcileAndGet()
+	if podSpec.PriorityClassName != "" || len(podSpec.ImagePullSecrets) != 0 {
+		t.Fatalf("Expected the overrides to be removed but got %v", podSpec)
+	}
+}
+
+// TestReconcileDynamicProvisioning covers enabling dynamic provisioning in the OperatorConfig,
+// changing the StorageClass, and disabling it again.
+func TestReconcileDynamicProvisioning(t *testing.T) {
+	checkNumStatics(t)
+
+	ctx := context.TODO()
+	logger, r := setup()
+	// The controller gets the region from the cluster's Infrastructure
+	err := r.client.Create(ctx, &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS:  &configv1.AWSPlatformStatus{Region: "us-east-2"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureStatics(context.TODO(), logger, r.client); err != nil {
+		t.Fatal(err)
+	}
+	req := reconcile.Request{NamespacedName: configNamespacedName}
+	reconcileConfig := func() {
+		t.Helper()
+		if res, err := r.Reconcile(context.TODO(), req); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+			t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+	// checkProvisioning checks whether the provisioning statics exist, and returns the StorageClass.
+	checkProvisioning := func(expectExist bool) *storagev1.StorageClass {
+		t.Helper()
+		var sc *storagev1.StorageClass
+		for _, s := range provisioningStatics {
+			obj := s.GetType()
+			err := r.client.Get(ctx, s.GetNamespacedName(), obj)
+			if expectExist && err != nil {
+				t.Fatalf("Expected %v to exist but got %v", s.GetNamespacedName(), err)
+			}
+			if !expectExist && !errors.IsNotFound(err) {
+				t.Fatalf("Expected %v not to exist but got %v", s.GetNamespacedName(), err)
+			}
+			if found, ok := obj.(*storagev1.StorageClass); ok {
+				sc = found
+			}
+		}
+		return sc
+	}
+
+	// Disabled by default
+	checkProvisioning(false)
+	// Reconciling a disabled static doesn't create it
+	scReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: provisioningStorageClassName}}
+	if res, err := r.Reconcile(context.TODO(), scReq); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	checkProvisioning(false)
+
+	// Enable it
+	config := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+		Spec: awsefsv1alpha1.OperatorConfigSpec{
+			DynamicProvisioning: &awsefsv1alpha1.DynamicProvisioning{FileSystemID: "fs-123abc"},
+		},
+	}
+	if err := r.client.Create(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+	sc := checkProvisioning(true)
+	if fsid := sc.Parameters["fileSystemId"]; fsid != "fs-123abc" {
+		t.Fatalf("Expected fileSystemId fs-123abc but got %q", fsid)
+	}
+	deploy := &appsv1.Deployment{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: controllerDeploymentName}, deploy); err != nil {
+		t.Fatal(err)
+	}
+	env := deploy.Spec.Template.Spec.Containers[0].Env
+	if last := env[len(env)-1]; last.Name != "AWS_REGION" || last.Value != "us-east-2" {
+		t.Fatalf("Expected the controller to get AWS_REGION=us-east-2 but got %v", env)
+	}
+	if orefs := deploy.GetOwnerReferences(); len(orefs) != 1 || orefs[0].Name != svCRDName {
+		t.Fatalf("Expected the Deployment to be owned by the CRD but got %v", orefs)
+	}
+
+	// StorageClass parameters are immutable, so changing them replaces the StorageClass.
+	refreshConfig(t, r.client, config)
+	config.Spec.DynamicProvisioning.FileSystemID = "fs-456def"
+	config.Spec.DynamicProvisioning.BasePath = "/dynamic"
+	if err := r.client.Update(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+	sc = checkProvisioning(true)
+	if fsid, bp := sc.Parameters["fileSystemId"], sc.Parameters["basePath"]; fsid != "fs-456def" || bp != "/dynamic" {
+		t.Fatalf("Expected fileSystemId fs-456def and basePath /dynamic but got %v", sc.Parameters)
+	}
+
+	// Disable it again
+	refreshConfig(t, r.client, config)
+	config.Spec.DynamicProvisioning = nil
+	if err := r.client.Update(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+	checkProvisioning(false)
+	// The always-on statics are still there
+	checkStatics(t, r.client)
+
+	if err := r.client.Delete(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+}
+
+// TestReconcileStorageClasses covers adding, changing and removing StorageClasses in the
+// OperatorConfig.
+func TestReconcileStorageClasses(t *testing.T) {
+	ctx := context.TODO()
+	logger, r := setup()
+	err := r.client.Create(ctx, &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS:  &configv1.AWSPlatformStatus{Region: "us-east-2"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureStatics(context.TODO(), logger, r.client); err != nil {
+		t.Fatal(err)
+	}
+	req := reconcile.Request{NamespacedName: configNamespacedName}
+	reconcileConfig := func() {
+		t.Helper()
+		if res, err := r.Reconcile(context.TODO(), req); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+			t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+		}
+	}
+	// getClasses returns the StorageClasses we manage, by name
+	getClasses := func() map[string]storagev1.StorageClass {
+		t.Helper()
+		scList := &storagev1.StorageClassList{}
+		if err := r.client.List(ctx, scList, util.ICareSelector()); err != nil {
+			t.Fatal(err)
+		}
+		classes := make(map[string]storagev1.StorageClass)
+		for _, sc := range scList.Items {
+			classes[sc.Name] = sc
+		}
+		return classes
+	}
+	controllerExists := func() bool {
+		t.Helper()
+		nsname := types.NamespacedName{Namespace: namespaceName, Name: controllerDeploymentName}
+		err := r.client.Get(ctx, nsname, &appsv1.Deployment{})
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	retain := corev1.PersistentVolumeReclaimRetain
+	config := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+		Spec: awsefsv1alpha1.OperatorConfigSpec{
+			StorageClasses: []awsefsv1alpha1.StorageClassConfig{
+				{Name: "efs-scratch", MountOptions: []string{"tls"}},
+				{Name: "efs-durable", ReclaimPolicy: &retain, FileSystemID: "fs-123abc", DirectoryPerms: "750"},
+				// Can't replace a built-in one
+				{Name: StorageClassName, FileSystemID: "fs-456def"},
+			},
+		},
+	}
+	if err := r.client.Create(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+	classes := getClasses()
+	if len(classes) != 3 {
+		t.Fatalf("Expected the default StorageClass plus two but got %v", classes)
+	}
+	if scratch := classes["efs-scratch"]; !reflect.DeepEqual(scratch.MountOptions, []string{"tls"}) ||
+		len(scratch.Parameters) != 0 {
+		t.Fatalf("Expected efs-scratch to have mount option tls and no parameters but got %v", scratch)
+	}
+	durable := classes["efs-durable"]
+	if *durable.ReclaimPolicy != retain || durable.Parameters["fileSystemId"] != "fs-123abc" ||
+		durable.Parameters["directoryPerms"] != "750" {
+		t.Fatalf("Expected efs-durable to Retain and provision on fs-123abc with 750 but got %v", durable)
+	}
+	if len(classes[StorageClassName].Parameters) != 0 {
+		t.Fatalf("Expected the default StorageClass to be left alone but got %v", classes[StorageClassName])
+	}
+	// efs-durable needs the controller, but not its StorageClass
+	if !controllerExists() {
+		t.Fatal("Expected the controller to be deployed")
+	}
+
+	// Reconciling one of the classes restores it
+	if err := r.client.Delete(ctx, &durable); err != nil {
+		t.Fatal(err)
+	}
+	durableReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: "efs-durable"}}
+	if res, err := r.Reconcile(context.TODO(), durableReq); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	if _, ok := getClasses()["efs-durable"]; !ok {
+		t.Fatal("Expected efs-durable to be restored")
+	}
+
+	// Changing a class replaces it; removing one deletes it, as does removing the last one that
+	// needs the controller.
+	refreshConfig(t, r.client, config)
+	config.Spec.StorageClasses = []awsefsv1alpha1.StorageClassConfig{
+		{Name: "efs-durable", ReclaimPolicy: &retain},
+	}
+	if err := r.client.Update(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+	classes = getClasses()
+	if _, ok := classes["efs-scratch"]; ok || len(classes) != 2 {
+		t.Fatalf("Expected efs-scratch to be deleted but got %v", classes)
+	}
+	if durable = classes["efs-durable"]; len(durable.Parameters) != 0 {
+		t.Fatalf("Expected efs-durable to lose its parameters but got %v", durable)
+	}
+	if controllerExists() {
+		t.Fatal("Expected the controller to be deleted")
+	}
+	// Reconciling a deleted class doesn't bring it back
+	scratchReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: "efs-scratch"}}
+	if res, err := r.Reconcile(context.TODO(), scratchReq); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+
+	// A class we created, but don't remember, e.g. removed from the OperatorConfig while the
+	// operator was down, gets cleaned up.
+	stale := storageClassDefinition(awsefsv1alpha1.StorageClassConfig{Name: "efs-stale"})
+	if err := r.client.Create(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.client.Delete(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	reconcileConfig()
+	if classes = getClasses(); len(classes) != 1 {
+		t.Fatalf("Expected only the default StorageClass but got %v", classes)
+	}
+	checkStatics(t, r.client)
+}
+
 // TestReconcileUnexpected tests the code path where a resource we don't care about somehow makes it past the filter
 func TestReconcileUnexpected(t *testing.T) {
 	_, r := setup()
 	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}}
-	res, err := r.Reconcile(req)
+	res, err := r.Reconcile(context.TODO(), req)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -315,9 +701,9 @@ func TestReconcileEnsureFails(t *testing.T) {
 	// Any resource is fine, just making sure we actually try to Ensure it
 	staticResource := staticResources[3]
 
-	rs := ReconcileStatics{client: fcwce, scheme: scheme.Scheme}
+	rs := ReconcileStatics{client: fcwce, scheme: scheme.Scheme, configured: true}
 
-	res, err := rs.Reconcile(reconcile.Request{NamespacedName: staticResource.GetNamespacedName()})
+	res, err := rs.Reconcile(context.TODO(), reconcile.Request{NamespacedName: staticResource.GetNamespacedName()})
 
 	if err == nil {
 		t.Fatal("Expected an error")
@@ -326,3 +712,192 @@ func TestReconcileEnsureFails(t *testing.T) {
 		t.Fatalf("Expected a requeue, got %v", res)
 	}
 }
+
+// flakyClient fails to create or apply SecurityContextConstraints, as if that API were
+// unavailable, the first `failures` times.
+type flakyClient struct {
+	crclient.Client
+	mutex    sync.Mutex
+	failures int
+}
+
+func (c *flakyClient) Create(ctx context.Context, obj crclient.Object, opts ...crclient.CreateOption) error {
+	if err := c.fail(obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *flakyClient) Patch(ctx context.Context, obj crclient.Object, patch crclient.Patch, opts ...crclient.PatchOption) error {
+	if err := c.fail(obj); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// fail returns an error if `obj` is SecurityContextConstraints and failures remain.
+func (c *flakyClient) fail(obj crclient.Object) error {
+	if _, ok := obj.(*securityv1.SecurityContextConstraints); ok {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if c.failures > 0 {
+			c.failures--
+			return errors.NewServiceUnavailable("try again later")
+		}
+	}
+	return nil
+}
+
+func (c *flakyClient) setFailures(failures int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.failures = failures
+}
+
+// TestFirstReconcile makes sure the first request, whatever it's for, gets a pass over all the
+// statics with the OperatorConfig applied, so the override isn't undone.
+func TestFirstReconcile(t *testing.T) {
+	ctx := context.TODO()
+	_, r := setup()
+	r.configured = false
+
+	config := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+		Spec:       awsefsv1alpha1.OperatorConfigSpec{DriverImage: "mirror.example.com/efs-csi-driver:v1"},
+	}
+	if err := r.client.Create(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	defer applyConfig(nil, "")
+
+	dsReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespaceName, Name: daemonSetName}}
+	if res, err := r.Reconcile(ctx, dsReq); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	if !r.configured {
+		t.Fatal("Expected the reconciler to be configured")
+	}
+	// All the statics were created, not just the DaemonSet
+	checkStatics(t, r.client)
+	ds := &appsv1.DaemonSet{}
+	if err := r.client.Get(ctx, dsReq.NamespacedName, ds); err != nil {
+		t.Fatal(err)
+	}
+	if image := ds.Spec.Template.Spec.Containers[0].Image; image != "mirror.example.com/efs-csi-driver:v1" {
+		t.Fatalf("Expected the DaemonSet to have the override image but got %s", image)
+	}
+}
+
+// TestReconcileDegraded makes sure statics that fail are reported in the OperatorConfig's status,
+// and the pass requeued, until they succeed.
+func TestReconcileDegraded(t *testing.T) {
+	ctx := context.TODO()
+	_, r := setup()
+	client := &flakyClient{Client: r.client}
+	r.client = client
+	r.configured = false
+
+	config := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+	}
+	if err := client.Create(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	checkDegraded := func(expected metav1.ConditionStatus, expectedFailed ...string) {
+		t.Helper()
+		config := &awsefsv1alpha1.OperatorConfig{}
+		if err := client.Get(ctx, configNamespacedName, config); err != nil {
+			t.Fatal(err)
+		}
+		cond := meta.FindStatusCondition(config.Status.Conditions, awsefsv1alpha1.OperatorConfigDegraded)
+		if cond == nil || cond.Status != expected {
+			t.Fatalf("Expected Degraded=%s but got %v", expected, cond)
+		}
+		if !reflect.DeepEqual(config.Status.FailedStatics, expectedFailed) {
+			t.Fatalf("Expected failed statics %v but got %v", expectedFailed, config.Status.FailedStatics)
+		}
+	}
+	req := reconcile.Request{NamespacedName: configNamespacedName}
+
+	// A failure isn't retried within the reconcile: it's reported, and the error requeues the pass.
+	client.setFailures(1)
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatal("Expected an error")
+	} else if _, ok := err.(*EnsureError); !ok {
+		t.Fatalf("Expected an *EnsureError but got %T: %v", err, err)
+	}
+	checkDegraded(metav1.ConditionTrue, "SecurityContextConstraints/efs-csi-scc")
+	if degraded := testutil.ToFloat64(metrics.StaticsDegraded); degraded != 1 {
+		t.Fatalf("Expected the statics_degraded metric to be 1 but got %v", degraded)
+	}
+	if !r.configured {
+		t.Fatal("Expected the reconciler to be configured despite the failure")
+	}
+	// The DaemonSet waits for the SecurityContextConstraints its pods need
+	dsNSName := types.NamespacedName{Namespace: namespaceName, Name: daemonSetName}
+	if err := client.Get(ctx, dsNSName, &appsv1.DaemonSet{}); !errors.IsNotFound(err) {
+		t.Fatalf("Expected no DaemonSet, but got %v", err)
+	}
+
+	// Once the API is back, the requeued reconcile heals the statics and clears the condition.
+	if res, err := r.Reconcile(ctx, req); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	checkDegraded(metav1.ConditionFalse)
+	if degraded := testutil.ToFloat64(metrics.StaticsDegraded); degraded != 0 {
+		t.Fatalf("Expected the statics_degraded metric to be 0 but got %v", degraded)
+	}
+	if err := client.Get(ctx, types.NamespacedName{Name: sccName}, &securityv1.SecurityContextConstraints{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Get(ctx, dsNSName, &appsv1.DaemonSet{}); err != nil {
+		t.Fatal(err)
+	}
+	if !Ensured.IsSet() {
+		t.Fatal("Expected the statics to be marked ensured")
+	}
+}
+
+// TestReconcileDegradedWithoutConfig makes sure that, without an OperatorConfig, a failure is
+// reported with an Event on the SharedVolume CRD rather than by creating one.
+func TestReconcileDegradedWithoutConfig(t *testing.T) {
+	ctx := context.TODO()
+	_, r := setup()
+	client := &flakyClient{Client: r.client}
+	r.client = client
+	r.configured = false
+	recorder := r.recorder.(*record.FakeRecorder)
+	req := reconcile.Request{NamespacedName: configNamespacedName}
+
+	client.setFailures(1)
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if err := client.Get(ctx, configNamespacedName, &awsefsv1alpha1.OperatorConfig{}); !errors.IsNotFound(err) {
+		t.Fatalf("Expected no OperatorConfig, but got %v", err)
+	}
+	expectEvent := "Warning StaticsFailed Encountered 1 error(s) ensuring statics: SecurityContextConstraints/efs-csi-scc"
+	var events []string
+	for _, e := range test.DrainEvents(recorder) {
+		// Ignore those about the statics that were created
+		if strings.Contains(e, reasonStaticsFailed) {
+			events = append(events, e)
+		}
+	}
+	if !reflect.DeepEqual(events, []string{expectEvent}) {
+		t.Fatalf("Expected event %q but got %v", expectEvent, events)
+	}
+
+	// Success is nothing to report
+	if res, err := r.Reconcile(ctx, req); err != nil || !reflect.DeepEqual(res, test.NullResult) {
+		t.Fatalf("Expected no requeue, no error; got\nresult: %v\nerr: %v", res, err)
+	}
+	if err := client.Get(ctx, configNamespacedName, &awsefsv1alpha1.OperatorConfig{}); !errors.IsNotFound(err) {
+		t.Fatalf("Expected no OperatorConfig, but got %v", err)
+	}
+	for _, e := range test.DrainEvents(recorder) {
+		if strings.Contains(e, reasonStaticsFailed) {
+			t.Fatalf("Expected no failure event but got %q", e)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package statics
 import (
 	"testing"
 
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
 	"openshift/aws-efs-operator/pkg/test"
 	"openshift/aws-efs-operator/pkg/util"
 
@@ -13,14 +14,14 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	expectedNumStatics = 5
+	expectedNumStatics             = 5
+	expectedNumProvisioningStatics = 5
 )
 
 // checkNumStatics is a helper to guard against static resources being added in the future without tests
@@ -30,18 +31,31 @@ func checkNumStatics(t *testing.T) {
 		t.Fatalf("Test update needed! Expected %d static resources but found %d.",
 			expectedNumStatics, numStatics)
 	}
+	if numStatics := len(provisioningStatics); numStatics != expectedNumProvisioningStatics {
+		t.Fatalf("Test update needed! Expected %d dynamic provisioning resources but found %d.",
+			expectedNumProvisioningStatics, numStatics)
+	}
+}
+
+// refreshConfig re-reads `config`, whose status the reconciler may have updated since the test
+// last wrote it.
+func refreshConfig(t *testing.T, client crclient.Client, config *awsefsv1alpha1.OperatorConfig) {
+	t.Helper()
+	if err := client.Get(context.TODO(), configNamespacedName, config); err != nil {
+		t.Fatal(err)
+	}
 }
 
 // checkStatics queries the client for all the known static resources, verifying that they exist
 // and have the expected content. It returns a map, keyed by the short name of the resource type
-// (e.g. "SecurityContextConstraints") of the runtime.Object returned by the client for each resource.
-func checkStatics(t *testing.T, client crclient.Client) map[string]runtime.Object {
-	ret := make(map[string]runtime.Object)
+// (e.g. "SecurityContextConstraints") of the object returned by the client for each resource.
+func checkStatics(t *testing.T, client crclient.Client) map[string]crclient.Object {
+	ret := make(map[string]crclient.Object)
 	ctx := context.TODO()
 
 	for _, i := range []struct {
 		name   string
-		obj    runtime.Object
+		obj    crclient.Object
 		nsname types.NamespacedName
 	}{
 		{
@@ -73,7 +87,12 @@ func checkStatics(t *testing.T, client crclient.Client) map[string]runtime.Objec
 		if err := client.Get(ctx, i.nsname, i.obj); err != nil {
 			t.Fatalf("Couldn't get %s: %v", i.name, err)
 		}
-		test.DoDiff(t, findStatic(i.nsname).(*util.EnsurableImpl).Definition, i.obj, true)
+		e := findStatic(i.nsname).(*util.EnsurableImpl)
+		// A copy is created, labeled and with the OwnerReference (which TestStartup checks)
+		expected := e.Definition.DeepCopyObject().(crclient.Object)
+		util.MakeMeCare(expected)
+		expected.SetOwnerReferences(i.obj.GetOwnerReferences())
+		test.DoDiff(t, expected, i.obj, true)
 		ret[i.name] = i.obj
 	}
 
@@ -2,7 +2,12 @@ package statics
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
 	"openshift/aws-efs-operator/pkg/fixtures"
+	"openshift/aws-efs-operator/pkg/test"
 	"openshift/aws-efs-operator/pkg/util"
 	"testing"
 
@@ -12,10 +17,12 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	// TODO: pkg/client/fake is deprecated, replace with pkg/envtest
 	// nolint:staticcheck
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -28,17 +35,20 @@ func TestEnsureStatics(t *testing.T) {
 
 	logger := logf.Log.Logger
 	ctx := context.TODO()
-	var statics map[string]runtime.Object
+	var statics map[string]crclient.Object
 
 	// OpenShift types need to be registered explicitly
 	scheme.Scheme.AddKnownTypes(securityv1.SchemeGroupVersion, &securityv1.SecurityContextConstraints{})
+	// And so do ours
+	scheme.Scheme.AddKnownTypes(awsefsv1alpha1.SchemeGroupVersion, &awsefsv1alpha1.OperatorConfig{})
 
 	// Bootstrap: no resources exist yet
-	mockClient := fake.NewFakeClientWithScheme(scheme.Scheme)
+	// The CSIDriver is applied, which the fake client can't do alone
+	mockClient := &test.ApplyingClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
 
 	logger.Info("==> Phase: Bootstrap")
 
-	if err := EnsureStatics(logger, mockClient); err != nil {
+	if err := EnsureStatics(context.TODO(), logger, mockClient); err != nil {
 		t.Fatalf("EnsureStatics (bootstrap) failed with %v", err)
 	}
 
@@ -49,7 +59,7 @@ func TestEnsureStatics(t *testing.T) {
 
 	logger.Info("==> Phase: Steady state (if everything is as it should be, EnsureStatics should be effectively a no-op.)")
 
-	if err := EnsureStatics(logger, mockClient); err != nil {
+	if err := EnsureStatics(context.TODO(), logger, mockClient); err != nil {
 		t.Fatalf("EnsureStatics (steady state) failed with %v", err)
 	}
 	statics = checkStatics(t, mockClient)
@@ -90,6 +100,15 @@ func TestEnsureStatics(t *testing.T) {
 		t.Fatalf("Failed to update DaemonSet: %v", err)
 	}
 
+	// Flip the CSIDriver's spec, and label it as an admin might
+	cd := statics["CSIDriver"].(*storagev1.CSIDriver)
+	trueVal := true
+	cd.Spec.AttachRequired = &trueVal
+	cd.Labels["example.com/owner"] = "team-a"
+	if err := mockClient.Update(ctx, cd); err != nil {
+		t.Fatalf("Failed to update CSIDriver: %v", err)
+	}
+
 	// Delete the StorageClass
 	sc := statics["StorageClass"].(*storagev1.StorageClass)
 	if err := mockClient.Delete(ctx, sc); err != nil {
@@ -99,12 +118,54 @@ func TestEnsureStatics(t *testing.T) {
 	// Having made a righteous mess, prove EnsureStatics fixes it.
 	logger.Info("==> Phase: Recover")
 
-	if err := EnsureStatics(logger, mockClient); err != nil {
+	if err := EnsureStatics(context.TODO(), logger, mockClient); err != nil {
 		t.Fatalf("EnsureStatics (recover) failed with %v", err)
 	}
-	checkStatics(t, mockClient)
+	statics = checkStatics(t, mockClient)
+	// The CSIDriver is applied, so the label we don't own survives
+	if owner := statics["CSIDriver"].GetLabels()["example.com/owner"]; owner != "team-a" {
+		t.Fatalf("Expected the CSIDriver's label to be left alone but got %q", owner)
+	}
 
 	logger.Info("<== Phase: Recover")
+
+	logger.Info("==> Phase: Server defaults (fields filled in by the server shouldn't cause updates)")
+
+	ds = statics["DaemonSet"].(*appsv1.DaemonSet)
+	ds.Spec.RevisionHistoryLimit = new(int32)
+	ds.Spec.Template.Spec.SchedulerName = "default-scheduler"
+	ds.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	if err := mockClient.Update(ctx, ds); err != nil {
+		t.Fatalf("Failed to update DaemonSet: %v", err)
+	}
+	cd = statics["CSIDriver"].(*storagev1.CSIDriver)
+	cd.Spec.StorageCapacity = new(bool)
+	if err := mockClient.Update(ctx, cd); err != nil {
+		t.Fatalf("Failed to update CSIDriver: %v", err)
+	}
+
+	if err := EnsureStatics(context.TODO(), logger, mockClient); err != nil {
+		t.Fatalf("EnsureStatics (server defaults) failed with %v", err)
+	}
+	for _, i := range []struct {
+		before crclient.Object
+		after  crclient.Object
+	}{
+		{ds, &appsv1.DaemonSet{}},
+		{cd, &storagev1.CSIDriver{}},
+	} {
+		before := i.before
+		nsname := types.NamespacedName{Namespace: before.GetNamespace(), Name: before.GetName()}
+		if err := mockClient.Get(ctx, nsname, i.after); err != nil {
+			t.Fatalf("Couldn't get %s: %v", nsname, err)
+		}
+		if rv := i.after.GetResourceVersion(); rv != before.GetResourceVersion() {
+			t.Fatalf("Expected %s not to be updated, but its ResourceVersion went from %s to %s",
+				nsname, before.GetResourceVersion(), rv)
+		}
+	}
+
+	logger.Info("<== Phase: Server defaults")
 }
 
 // TestEnsureStaticsError tests the error path of EnsureStatics
@@ -118,23 +179,88 @@ func TestEnsureStaticsError(t *testing.T) {
 	// Not realistic, we're just contriving a way to make Ensure fail
 	theError := fixtures.AlreadyExists
 
-	// We don't care about the calls, really, but we have to register them or gomock gets upset
+	// There's no OperatorConfig
+	client.EXPECT().
+		Get(gomock.Any(), configNamespacedName, &awsefsv1alpha1.OperatorConfig{}).
+		Return(fixtures.NotFound)
+	// We don't care about the calls, really, but we have to register them or gomock gets upset.
+	// Dynamic provisioning is disabled, so those statics get (retrieved to be) deleted. The
+	// DaemonSet isn't attempted, since the statics it needs failed.
+	attempted := expectedNumStatics + expectedNumProvisioningStatics - 1
 	client.EXPECT().
 		Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Times(expectedNumStatics).
+		Times(attempted).
 		Return(theError)
 	log.EXPECT().
 		Error(theError, "Failed to retrieve.", "resource", gomock.Any()).
-		Times(expectedNumStatics)
+		Times(attempted)
+	// Then we look for StorageClasses no longer in the OperatorConfig
+	client.EXPECT().
+		List(gomock.Any(), &storagev1.StorageClassList{}, gomock.Any()).
+		Return(theError)
+	log.EXPECT().
+		Error(theError, "Failed to list StorageClasses.")
 
-	err := EnsureStatics(log, client)
+	err := EnsureStatics(context.TODO(), log, client)
 	if err == nil {
 		t.Fatal("Expected EnsureStatics to fail hard.")
 	}
-	// Check the error count in the string. It should fail for all of the statics
-	expected := fmt.Sprintf("Encountered %d error(s) ensuring statics", expectedNumStatics)
-	if err.Error() != expected {
-		t.Fatalf("Unexpected error message.\nExpected: %s\nGot:      %s", expected, err.Error())
+	// It should fail for all of the statics attempted, and the List
+	ensureErr, ok := err.(*EnsureError)
+	if !ok {
+		t.Fatalf("Expected an *EnsureError but got %T: %v", err, err)
+	}
+	if len(ensureErr.Failed) != attempted+1 {
+		t.Fatalf("Expected the statics attempted and the StorageClass list to fail, but got %v", ensureErr.Failed)
+	}
+	expected := fmt.Sprintf("Encountered %d error(s) ensuring statics: ", attempted+1)
+	if !strings.HasPrefix(err.Error(), expected) || !strings.Contains(err.Error(), "SecurityContextConstraints/efs-csi-scc") ||
+		strings.Contains(err.Error(), "DaemonSet") || !strings.HasSuffix(err.Error(), "StorageClass/*") {
+		t.Fatalf("Unexpected error message: %s", err.Error())
+	}
+}
+
+// TestApplyConfigUnchanged makes sure that applying the same config again keeps the Definitions,
+// and with them the versions cached from the server.
+func TestApplyConfigUnchanged(t *testing.T) {
+	logger := logf.Log.Logger
+	defer applyConfig(nil, "")
+
+	scheme.Scheme.AddKnownTypes(securityv1.SchemeGroupVersion, &securityv1.SecurityContextConstraints{})
+	scheme.Scheme.AddKnownTypes(awsefsv1alpha1.SchemeGroupVersion, &awsefsv1alpha1.OperatorConfig{})
+
+	withClass := &awsefsv1alpha1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: awsefsv1alpha1.OperatorConfigName},
+		Spec: awsefsv1alpha1.OperatorConfigSpec{
+			StorageClasses: []awsefsv1alpha1.StorageClassConfig{{Name: "efs-scratch", MountOptions: []string{"tls"}}},
+		},
+	}
+	for _, config := range []*awsefsv1alpha1.OperatorConfig{nil, withClass} {
+		names := []string{daemonSetName, controllerDeploymentName, provisioningStorageClassName}
+		objs := []runtime.Object{}
+		if config != nil {
+			for _, c := range config.Spec.StorageClasses {
+				names = append(names, c.Name)
+			}
+			objs = append(objs, config.DeepCopy())
+		}
+		mockClient := &test.ApplyingClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme, objs...)}
+
+		applyConfig(config, "")
+		if err := EnsureStatics(context.TODO(), logger, mockClient); err != nil {
+			t.Fatalf("EnsureStatics failed with %v", err)
+		}
+		before := make(map[string]crclient.Object)
+		for _, name := range names {
+			before[name] = findStatic(types.NamespacedName{Name: name}).(*util.EnsurableImpl).Definition
+		}
+
+		applyConfig(config, "")
+		for name, def := range before {
+			if findStatic(types.NamespacedName{Name: name}).(*util.EnsurableImpl).Definition != def {
+				t.Errorf("Expected the Definition of %s to be kept.", name)
+			}
+		}
 	}
 }
 
@@ -201,131 +327,110 @@ func Test_AlwaysEqual(t *testing.T) {
 	}
 }
 
-func Test_storageClassEqual(t *testing.T) {
-	sc1 := staticResourceMap[StorageClassName].(*util.EnsurableImpl).Definition.(*storagev1.StorageClass)
-	sc2 := sc1.DeepCopy()
-
-	if !util.EqualOtherThanMeta(sc1, sc1) {
-		t.Error("Expected object to compare equal to itself.")
-	}
-
-	if !util.EqualOtherThanMeta(sc1, sc2) {
-		t.Errorf("Getter should always return objects that compare equal.\n%v\n%v", sc1, sc2)
-	}
-
-	// Mucking with metadata shouldn't affect equality
-	sc2.ObjectMeta.SelfLink = "/foo/bar/baz"
-	if !util.EqualOtherThanMeta(sc1, sc2) {
-		t.Errorf("Metadata should not affect equality.\n%v\n%v", sc1, sc2)
-	}
-
-	// But these fields should
-	sc2.Provisioner = "foo"
-	if util.EqualOtherThanMeta(sc1, sc2) {
-		t.Errorf("Change of Provisioner should make these unequal.\n%v\n%v", sc1, sc2)
-	}
-	// reset
-	sc2.Provisioner = sc1.Provisioner
-
-	recycle := corev1.PersistentVolumeReclaimRecycle
-	sc2.ReclaimPolicy = &recycle
-	if util.EqualOtherThanMeta(sc1, sc2) {
-		t.Errorf("Change of ReclaimPolicy should make these unequal.\n%v\n%v", sc1, sc2)
-	}
-	// reset
-	sc2.ReclaimPolicy = sc1.ReclaimPolicy
-
-	wf1c := storagev1.VolumeBindingWaitForFirstConsumer
-	sc2.VolumeBindingMode = &wf1c
-	if util.EqualOtherThanMeta(sc1, sc2) {
-		t.Errorf("Change of VolumeBindingMode should make these unequal.\n%v\n%v", sc1, sc2)
-	}
-}
-
-func Test_csiDriverEqual(t *testing.T) {
-	cd1 := staticResourceMap[CSIDriverName].(*util.EnsurableImpl).Definition.(*storagev1.CSIDriver)
-	cd2 := cd1.DeepCopy()
-
-	if !csiDriverEqual(cd1, cd1) {
-		t.Error("Expected object to compare equal to itself.")
-	}
-
-	if !csiDriverEqual(cd1, cd2) {
-		t.Errorf("Getter should always return objects that compare equal.\n%v\n%v", cd1, cd2)
-	}
-
-	// Mucking with metadata shouldn't affect equality
-	cd2.ObjectMeta.SelfLink = "/foo/bar/baz"
-	if !csiDriverEqual(cd1, cd2) {
-		t.Errorf("Metadata should not affect equality.\n%v\n%v", cd1, cd2)
-	}
-
-	// But anything in the Spec should
-	trueVal := true
-	cd2.Spec.AttachRequired = &trueVal
-	if csiDriverEqual(cd1, cd2) {
-		t.Errorf("Change in Spec should make these unequal.\n%v\n%v", cd1, cd2)
-	}
-}
-
-func Test_securityContextConstraintsEqual(t *testing.T) {
-	scc1 := staticResourceMap[sccName].(*util.EnsurableImpl).Definition.(*securityv1.SecurityContextConstraints)
-	scc2 := scc1.DeepCopy()
-
-	if !util.EqualOtherThanMeta(scc1, scc1) {
-		t.Error("Expected object to compare equal to itself.")
-	}
-
-	if !util.EqualOtherThanMeta(scc1, scc2) {
-		t.Errorf("Getter should always return objects that compare equal.\n%v\n%v", scc1, scc2)
-	}
-
-	// Mucking with metadata shouldn't affect equality
-	scc2.ObjectMeta.SelfLink = "/foo/bar/baz"
-	if !util.EqualOtherThanMeta(scc1, scc2) {
-		t.Errorf("Metadata should not affect equality.\n%v\n%v", scc1, scc2)
-	}
-
-	// Pick a few fields to test
-	scc2.AllowHostIPC = false
-	if util.EqualOtherThanMeta(scc1, scc2) {
-		t.Errorf("Changing AllowHostIPC should make these unequal.\n%v\n%v", scc1, scc2)
+func Test_daemonSetDefinition(t *testing.T) {
+	def := daemonSetDefinition(nil)
+	if def.Namespace != namespaceName {
+		t.Fatalf("Expected namespace %q but got %q", namespaceName, def.Namespace)
+	}
+
+	logLevel := int32(2)
+	spec := &awsefsv1alpha1.OperatorConfigSpec{
+		DriverImage:        "mirror.example.com/efs-csi-driver:v1",
+		RegistrarImage:     "mirror.example.com/registrar:v1",
+		LivenessProbeImage: "mirror.example.com/livenessprobe:v1",
+		ImagePullPolicy:    corev1.PullIfNotPresent,
+		LogLevel:           &logLevel,
+		ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "mirror-pull-secret"}},
+	}
+	def = daemonSetDefinition(spec)
+	podSpec := def.Spec.Template.Spec
+	expectedImages := map[string]string{
+		driverContainer:        spec.DriverImage,
+		registrarContainer:     spec.RegistrarImage,
+		livenessProbeContainer: spec.LivenessProbeImage,
+	}
+	if len(podSpec.Containers) != len(expectedImages) {
+		t.Fatalf("Expected %d containers but got %d", len(expectedImages), len(podSpec.Containers))
+	}
+	for _, c := range podSpec.Containers {
+		if c.Image != expectedImages[c.Name] {
+			t.Fatalf("Expected container %s to have image %q but got %q", c.Name, expectedImages[c.Name], c.Image)
+		}
+		if c.ImagePullPolicy != corev1.PullIfNotPresent {
+			t.Fatalf("Expected container %s to have pull policy IfNotPresent but got %q", c.Name, c.ImagePullPolicy)
+		}
+		for _, arg := range c.Args {
+			if strings.HasPrefix(arg, "--v=") && arg != "--v=2" {
+				t.Fatalf("Expected container %s to have --v=2 but got %s", c.Name, arg)
+			}
+		}
 	}
-	scc2.AllowHostIPC = true
-
-	scc2.RunAsUser.Type = securityv1.RunAsUserStrategyMustRunAs
-	if util.EqualOtherThanMeta(scc1, scc2) {
-		t.Errorf("Changing RunAsUser.Type should make these unequal.\n%v\n%v", scc1, scc2)
+	if !reflect.DeepEqual(podSpec.ImagePullSecrets, spec.ImagePullSecrets) {
+		t.Fatalf("Expected imagePullSecrets %v but got %v", spec.ImagePullSecrets, podSpec.ImagePullSecrets)
 	}
-	scc2.RunAsUser.Type = securityv1.RunAsUserStrategyRunAsAny
 
-	scc2.Users = append(scc2.Users, "foo")
-	if util.EqualOtherThanMeta(scc1, scc2) {
-		t.Errorf("Changing Users should make these unequal.\n%v\n%v", scc1, scc2)
+	// Partial overrides leave the rest alone
+	def = daemonSetDefinition(&awsefsv1alpha1.OperatorConfigSpec{DriverImage: spec.DriverImage})
+	for _, c := range def.Spec.Template.Spec.Containers {
+		if c.Name != driverContainer && c.Image == expectedImages[c.Name] {
+			t.Fatalf("Expected container %s to keep its default image but got %q", c.Name, c.Image)
+		}
+		if c.ImagePullPolicy != corev1.PullAlways {
+			t.Fatalf("Expected container %s to keep pull policy Always but got %q", c.Name, c.ImagePullPolicy)
+		}
 	}
 }
 
-func Test_daemonSetEqual(t *testing.T) {
-	ds1 := staticResourceMap[daemonSetName].(*util.EnsurableImpl).Definition.(*appsv1.DaemonSet)
-	ds2 := ds1.DeepCopy()
-
-	if !daemonSetEqual(ds1, ds1) {
-		t.Error("Expected object to compare equal to itself.")
-	}
-
-	if !daemonSetEqual(ds1, ds2) {
-		t.Errorf("Getter should always return objects that compare equal.\n%v\n%v", ds1, ds2)
-	}
-
-	// Mucking with metadata shouldn't affect equality
-	ds2.ObjectMeta.SelfLink = "/foo/bar/baz"
-	if !daemonSetEqual(ds1, ds2) {
-		t.Errorf("Metadata should not affect equality.\n%v\n%v", ds1, ds2)
-	}
-
-	// But anything in the Spec should
-	ds2.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
-	if daemonSetEqual(ds1, ds2) {
-		t.Errorf("Change in Spec should make these unequal.\n%v\n%v", ds1, ds2)
+func Test_daemonSetDefinitionPlacement(t *testing.T) {
+	def := daemonSetDefinition(nil)
+	defaultSelector := def.Spec.Template.Spec.NodeSelector
+	if arch := defaultSelector[corev1.LabelArchStable]; arch != string(defaultArchitecture) {
+		t.Fatalf("Expected the default architecture to be %s but got %q", defaultArchitecture, arch)
+	}
+
+	// A single architecture replaces the one in the node selector
+	def = daemonSetDefinition(&awsefsv1alpha1.OperatorConfigSpec{
+		Architectures: []awsefsv1alpha1.Architecture{awsefsv1alpha1.ArchitectureARM64},
+	})
+	podSpec := def.Spec.Template.Spec
+	if arch := podSpec.NodeSelector[corev1.LabelArchStable]; arch != "arm64" || podSpec.Affinity != nil {
+		t.Fatalf("Expected arm64 in the node selector and no affinity but got\n%v\n%v", podSpec.NodeSelector, podSpec.Affinity)
+	}
+	if len(podSpec.NodeSelector) != len(defaultSelector) {
+		t.Fatalf("Expected the rest of the node selector to be left alone but got %v", podSpec.NodeSelector)
+	}
+
+	// More than one needs node affinity
+	def = daemonSetDefinition(&awsefsv1alpha1.OperatorConfigSpec{
+		NodeSelector:      map[string]string{"example.com/efs": "true"},
+		Architectures:     []awsefsv1alpha1.Architecture{awsefsv1alpha1.ArchitectureAMD64, awsefsv1alpha1.ArchitectureARM64},
+		Tolerations:       []corev1.Toleration{{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}},
+		PriorityClassName: "system-node-critical",
+	})
+	podSpec = def.Spec.Template.Spec
+	if !reflect.DeepEqual(podSpec.NodeSelector, map[string]string{"example.com/efs": "true"}) {
+		t.Fatalf("Expected the node selector to be replaced but got %v", podSpec.NodeSelector)
+	}
+	expectedAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      corev1.LabelArchStable,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"amd64", "arm64"},
+					}},
+				}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(podSpec.Affinity, expectedAffinity) {
+		t.Fatalf("Expected affinity\n%v\nbut got\n%v", expectedAffinity, podSpec.Affinity)
+	}
+	if len(podSpec.Tolerations) != 1 || podSpec.Tolerations[0].Key != "example.com/dedicated" {
+		t.Fatalf("Expected the tolerations to be replaced but got %v", podSpec.Tolerations)
+	}
+	if podSpec.PriorityClassName != "system-node-critical" {
+		t.Fatalf("Expected priority class system-node-critical but got %q", podSpec.PriorityClassName)
 	}
 }
@@ -0,0 +1,79 @@
+package statics
+
+/**
+Reporting how the statics are doing, in the OperatorConfig's status and for the readiness probe.
+*/
+
+import (
+	"context"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/health"
+	"openshift/aws-efs-operator/pkg/metrics"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons for the OperatorConfigDegraded condition
+const (
+	reasonAsExpected    = "AsExpected"
+	reasonStaticsFailed = "StaticsFailed"
+)
+
+// Ensured is a readiness check passing once the statics controller has ensured all the statics.
+var Ensured = health.NewFlag("static resources not yet ensured")
+
+// reportStatus records `err`, the outcome of EnsureStatics, in the StaticsDegraded metric and the
+// OperatorConfig's status. The OperatorConfig belongs to the cluster admin, so if there isn't one,
+// a failure is reported with an Event on the SharedVolume `crd` instead. Errors are logged rather
+// than returned, since they shouldn't stop us ensuring the statics.
+func reportStatus(ctx context.Context, log logr.Logger, client crclient.Client, recorder record.EventRecorder,
+	crd *apiextensions.CustomResourceDefinition, err error) {
+	condition := metav1.Condition{
+		Type:    awsefsv1alpha1.OperatorConfigDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonAsExpected,
+		Message: "All static resources are in place.",
+	}
+	var failed []string
+	if err != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonStaticsFailed
+		condition.Message = err.Error()
+		if ensureErr, ok := err.(*EnsureError); ok {
+			failed = ensureErr.Failed
+		}
+		metrics.StaticsDegraded.Set(1)
+	} else {
+		metrics.StaticsDegraded.Set(0)
+	}
+
+	config := &awsefsv1alpha1.OperatorConfig{}
+	if getErr := client.Get(ctx, configNamespacedName, config); getErr != nil {
+		if !errors.IsNotFound(getErr) {
+			log.Error(getErr, "Failed to retrieve OperatorConfig to report status.")
+		} else if err != nil {
+			recorder.Event(crd, corev1.EventTypeWarning, reasonStaticsFailed, condition.Message)
+		}
+		return
+	}
+
+	status := config.Status.DeepCopy()
+	condition.ObservedGeneration = config.Generation
+	meta.SetStatusCondition(&config.Status.Conditions, condition)
+	config.Status.FailedStatics = failed
+	if equality.Semantic.DeepEqual(status, &config.Status) {
+		return
+	}
+	if updateErr := client.Status().Update(ctx, config); updateErr != nil {
+		log.Error(updateErr, "Failed to update OperatorConfig status.")
+	}
+}
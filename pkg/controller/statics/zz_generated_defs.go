@@ -1,7 +1,12 @@
 // Code generated for package statics by go-bindata DO NOT EDIT. (@generated)
 // sources:
+// defs/controller-clusterrole.yaml
+// defs/controller-clusterrolebinding.yaml
+// defs/controller-deployment.yaml
+// defs/controller-serviceaccount.yaml
 // defs/csidriver.yaml
 // defs/daemonset.yaml
+// defs/provisioning-storageclass.yaml
 // defs/scc.yaml
 // defs/serviceaccount.yaml
 // defs/storageclass.yaml
@@ -57,9 +62,231 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
+var _defsControllerClusterroleYaml = []byte(`# Source: https://github.com/kubernetes-sigs/aws-efs-csi-driver/blob/release-1.3/deploy/kubernetes/base/controller-serviceaccount.yaml
+# Changes tagged with DELTA: comments
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: efs-csi-external-provisioner-role
+rules:
+  - apiGroups: [""]
+    resources: ["persistentvolumes"]
+    verbs: ["get", "list", "watch", "create", "delete"]
+  - apiGroups: [""]
+    resources: ["persistentvolumeclaims"]
+    verbs: ["get", "list", "watch", "update"]
+  - apiGroups: ["storage.k8s.io"]
+    resources: ["storageclasses"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: [""]
+    resources: ["events"]
+    verbs: ["list", "watch", "create", "update", "patch"]
+  - apiGroups: ["storage.k8s.io"]
+    resources: ["csinodes"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: [""]
+    resources: ["nodes"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["coordination.k8s.io"]
+    resources: ["leases"]
+    verbs: ["get", "watch", "list", "delete", "update", "create"]
+`)
+
+func defsControllerClusterroleYamlBytes() ([]byte, error) {
+	return _defsControllerClusterroleYaml, nil
+}
+
+func defsControllerClusterroleYaml() (*asset, error) {
+	bytes, err := defsControllerClusterroleYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "defs/controller-clusterrole.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _defsControllerClusterrolebindingYaml = []byte(`# Source: https://github.com/kubernetes-sigs/aws-efs-csi-driver/blob/release-1.3/deploy/kubernetes/base/controller-serviceaccount.yaml
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: efs-csi-provisioner-binding
+subjects:
+  - kind: ServiceAccount
+    name: efs-csi-controller-sa
+    # The namespace is populated dynamically by the operator.
+roleRef:
+  kind: ClusterRole
+  name: efs-csi-external-provisioner-role
+  apiGroup: rbac.authorization.k8s.io
+`)
+
+func defsControllerClusterrolebindingYamlBytes() ([]byte, error) {
+	return _defsControllerClusterrolebindingYaml, nil
+}
+
+func defsControllerClusterrolebindingYaml() (*asset, error) {
+	bytes, err := defsControllerClusterrolebindingYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "defs/controller-clusterrolebinding.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _defsControllerDeploymentYaml = []byte(`# Source: https://github.com/kubernetes-sigs/aws-efs-csi-driver/blob/release-1.3/deploy/kubernetes/base/controller-deployment.yaml
+# Changes tagged with DELTA: comments
+# Only deployed if dynamic provisioning is enabled in the OperatorConfig.
+kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: efs-csi-controller
+  # DELTA: Use a custom namespace rather than kube-system
+  # The namespace is populated dynamically by the operator.
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: efs-csi-controller
+  template:
+    metadata:
+      labels:
+        app: efs-csi-controller
+    spec:
+      # DELTA: Removed
+      # hostNetwork: true
+      # priorityClassName: system-cluster-critical
+      nodeSelector:
+        kubernetes.io/os: linux
+        # DELTA: Added, as for the DaemonSet
+        kubernetes.io/arch: amd64
+      serviceAccountName: efs-csi-controller-sa
+      tolerations:
+        - key: CriticalAddonsOnly
+          operator: Exists
+      containers:
+        - name: efs-plugin
+          # DELTA: Removed. Access points are created via the EFS API; no privilege needed.
+          # securityContext:
+          #   privileged: true
+          # DELTA: fq image. Dynamic provisioning needs at least v1.2.0.
+          image: registry.hub.docker.com/amazon/aws-efs-csi-driver:v1.3.2
+          # DELTA: Always pull
+          imagePullPolicy: Always
+          args:
+            - --endpoint=$(CSI_ENDPOINT)
+            - --logtostderr
+            - --v=5
+            - --delete-access-point-root-dir=false
+          env:
+            - name: CSI_ENDPOINT
+              value: unix:///var/lib/csi/sockets/pluginproxy/csi.sock
+            # DELTA: Added. The operator's credentials, and the region it discovered.
+            # See README.md.
+            - name: AWS_ACCESS_KEY_ID
+              valueFrom:
+                secretKeyRef:
+                  name: aws-efs-operator-credentials
+                  key: aws_access_key_id
+                  optional: true
+            - name: AWS_SECRET_ACCESS_KEY
+              valueFrom:
+                secretKeyRef:
+                  name: aws-efs-operator-credentials
+                  key: aws_secret_access_key
+                  optional: true
+            # The operator adds AWS_REGION.
+          volumeMounts:
+            - name: socket-dir
+              mountPath: /var/lib/csi/sockets/pluginproxy/
+          ports:
+            - name: healthz
+              containerPort: 9909
+              protocol: TCP
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: healthz
+            initialDelaySeconds: 10
+            timeoutSeconds: 3
+            periodSeconds: 10
+            failureThreshold: 5
+        - name: csi-provisioner
+          image: k8s.gcr.io/sig-storage/csi-provisioner:v2.1.1
+          # DELTA: Always pull
+          imagePullPolicy: Always
+          args:
+            - --csi-address=$(ADDRESS)
+            - --v=5
+            - --feature-gates=Topology=true
+            - --extra-create-metadata
+            - --leader-election
+          env:
+            - name: ADDRESS
+              value: /var/lib/csi/sockets/pluginproxy/csi.sock
+          volumeMounts:
+            - name: socket-dir
+              mountPath: /var/lib/csi/sockets/pluginproxy/
+        - name: liveness-probe
+          image: quay.io/k8scsi/livenessprobe:v2.0.0
+          # DELTA: Always pull
+          imagePullPolicy: Always
+          args:
+            - --csi-address=/csi/csi.sock
+            - --health-port=9909
+          volumeMounts:
+            - name: socket-dir
+              mountPath: /csi
+      volumes:
+        - name: socket-dir
+          emptyDir: {}
+`)
+
+func defsControllerDeploymentYamlBytes() ([]byte, error) {
+	return _defsControllerDeploymentYaml, nil
+}
+
+func defsControllerDeploymentYaml() (*asset, error) {
+	bytes, err := defsControllerDeploymentYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "defs/controller-deployment.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _defsControllerServiceaccountYaml = []byte(`# Service account for the EFS CSI driver's controller Deployment, which provisions access points.
+# Only deployed if dynamic provisioning is enabled in the OperatorConfig.
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: efs-csi-controller-sa
+  # NOTE: namespace is set dynamically after this is loaded.
+`)
+
+func defsControllerServiceaccountYamlBytes() ([]byte, error) {
+	return _defsControllerServiceaccountYaml, nil
+}
+
+func defsControllerServiceaccountYaml() (*asset, error) {
+	bytes, err := defsControllerServiceaccountYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "defs/controller-serviceaccount.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _defsCsidriverYaml = []byte(`# Source: https://github.com/kubernetes-sigs/aws-efs-csi-driver/blob/51d19a433dcfc47fbb7b7a0e1c8ff6ab98ce87e9/deploy/kubernetes/base/csidriver.yaml
 kind: CSIDriver
-apiVersion: storage.k8s.io/v1beta1
+apiVersion: storage.k8s.io/v1
 metadata:
   name: efs.csi.aws.com
 spec:
@@ -103,7 +330,7 @@ spec:
     spec:
       # DELTA: Added
       serviceAccountName: efs-csi-sa
-      # DELTA: Removed
+      # DELTA: Removed. Settable via the OperatorConfig, as are nodeSelector and tolerations.
       # priorityClassName: system-node-critical
       nodeSelector:
         kubernetes.io/os: linux
@@ -219,6 +446,37 @@ func defsDaemonsetYaml() (*asset, error) {
 	return a, nil
 }
 
+var _defsProvisioningStorageclassYaml = []byte(`# StorageClass through which PersistentVolumeClaims get access points provisioned.
+# Only deployed if dynamic provisioning is enabled in the OperatorConfig, which supplies
+# the fileSystemId and the other parameters.
+---
+kind: StorageClass
+apiVersion: storage.k8s.io/v1
+metadata:
+  name: efs-ap-sc
+provisioner: efs.csi.aws.com
+reclaimPolicy: Delete
+volumeBindingMode: Immediate
+parameters:
+  provisioningMode: efs-ap
+  directoryPerms: "700"
+`)
+
+func defsProvisioningStorageclassYamlBytes() ([]byte, error) {
+	return _defsProvisioningStorageclassYaml, nil
+}
+
+func defsProvisioningStorageclassYaml() (*asset, error) {
+	bytes, err := defsProvisioningStorageclassYamlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "defs/provisioning-storageclass.yaml", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _defsSccYaml = []byte(`allowHostDirVolumePlugin: true
 allowHostIPC: true
 allowHostNetwork: true
@@ -374,11 +632,16 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"defs/csidriver.yaml":      defsCsidriverYaml,
-	"defs/daemonset.yaml":      defsDaemonsetYaml,
-	"defs/scc.yaml":            defsSccYaml,
-	"defs/serviceaccount.yaml": defsServiceaccountYaml,
-	"defs/storageclass.yaml":   defsStorageclassYaml,
+	"defs/controller-clusterrole.yaml":        defsControllerClusterroleYaml,
+	"defs/controller-clusterrolebinding.yaml": defsControllerClusterrolebindingYaml,
+	"defs/controller-deployment.yaml":         defsControllerDeploymentYaml,
+	"defs/controller-serviceaccount.yaml":     defsControllerServiceaccountYaml,
+	"defs/csidriver.yaml":                     defsCsidriverYaml,
+	"defs/daemonset.yaml":                     defsDaemonsetYaml,
+	"defs/provisioning-storageclass.yaml":     defsProvisioningStorageclassYaml,
+	"defs/scc.yaml":                           defsSccYaml,
+	"defs/serviceaccount.yaml":                defsServiceaccountYaml,
+	"defs/storageclass.yaml":                  defsStorageclassYaml,
 }
 
 // AssetDir returns the file names below a certain
@@ -423,11 +686,16 @@ type bintree struct {
 
 var _bintree = &bintree{nil, map[string]*bintree{
 	"defs": &bintree{nil, map[string]*bintree{
-		"csidriver.yaml":      &bintree{defsCsidriverYaml, map[string]*bintree{}},
-		"daemonset.yaml":      &bintree{defsDaemonsetYaml, map[string]*bintree{}},
-		"scc.yaml":            &bintree{defsSccYaml, map[string]*bintree{}},
-		"serviceaccount.yaml": &bintree{defsServiceaccountYaml, map[string]*bintree{}},
-		"storageclass.yaml":   &bintree{defsStorageclassYaml, map[string]*bintree{}},
+		"controller-clusterrole.yaml":        &bintree{defsControllerClusterroleYaml, map[string]*bintree{}},
+		"controller-clusterrolebinding.yaml": &bintree{defsControllerClusterrolebindingYaml, map[string]*bintree{}},
+		"controller-deployment.yaml":         &bintree{defsControllerDeploymentYaml, map[string]*bintree{}},
+		"controller-serviceaccount.yaml":     &bintree{defsControllerServiceaccountYaml, map[string]*bintree{}},
+		"csidriver.yaml":                     &bintree{defsCsidriverYaml, map[string]*bintree{}},
+		"daemonset.yaml":                     &bintree{defsDaemonsetYaml, map[string]*bintree{}},
+		"provisioning-storageclass.yaml":     &bintree{defsProvisioningStorageclassYaml, map[string]*bintree{}},
+		"scc.yaml":                           &bintree{defsSccYaml, map[string]*bintree{}},
+		"serviceaccount.yaml":                &bintree{defsServiceaccountYaml, map[string]*bintree{}},
+		"storageclass.yaml":                  &bintree{defsStorageclassYaml, map[string]*bintree{}},
 	}},
 }}
 
@@ -0,0 +1,114 @@
+package efs
+
+// Client implementation backed by the real EFS API.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/efs/efsiface"
+)
+
+// infrastructureName is the name of the singleton Infrastructure resource describing the cluster.
+const infrastructureName = "cluster"
+
+type awsClient struct {
+	api efsiface.EFSAPI
+}
+
+// blank assignment to verify that awsClient implements Client
+var _ Client = &awsClient{}
+
+// NewClient returns a Client talking to the EFS API in the given AWS `region`. Credentials are
+// discovered via the default AWS credential chain, e.g. from the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables.
+func NewClient(region string) (Client, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, err
+	}
+	return &awsClient{api: efs.New(sess)}, nil
+}
+
+// NewClientForCluster returns a Client talking to the EFS API in the AWS region in which the
+// cluster is running, as discovered via `reader`.
+func NewClientForCluster(ctx context.Context, reader crclient.Reader) (Client, error) {
+	region, err := DiscoverRegion(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(region)
+}
+
+// DiscoverRegion finds the AWS region in which the cluster is running, from the cluster's
+// Infrastructure resource.
+func DiscoverRegion(ctx context.Context, reader crclient.Reader) (string, error) {
+	infra := &configv1.Infrastructure{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: infrastructureName}, infra); err != nil {
+		return "", err
+	}
+	ps := infra.Status.PlatformStatus
+	if ps == nil || ps.AWS == nil || ps.AWS.Region == "" {
+		return "", fmt.Errorf("couldn't discover AWS region from Infrastructure %q", infrastructureName)
+	}
+	return ps.AWS.Region, nil
+}
+
+// CreateAccessPoint implements Client.
+func (c *awsClient) CreateAccessPoint(ctx context.Context, req AccessPointRequest) (string, error) {
+	input := &efs.CreateAccessPointInput{
+		ClientToken:  aws.String(req.ClientToken),
+		FileSystemId: aws.String(req.FileSystemID),
+		PosixUser: &efs.PosixUser{
+			Uid: aws.Int64(req.Spec.PosixUser.UID),
+			Gid: aws.Int64(req.Spec.PosixUser.GID),
+		},
+	}
+	if len(req.Spec.PosixUser.SecondaryGIDs) != 0 {
+		input.PosixUser.SecondaryGids = aws.Int64Slice(req.Spec.PosixUser.SecondaryGIDs)
+	}
+	if rd := req.Spec.RootDirectory; rd != nil {
+		input.RootDirectory = &efs.RootDirectory{Path: aws.String(rd.Path)}
+		if ci := rd.CreationInfo; ci != nil {
+			input.RootDirectory.CreationInfo = &efs.CreationInfo{
+				OwnerUid:    aws.Int64(ci.OwnerUID),
+				OwnerGid:    aws.Int64(ci.OwnerGID),
+				Permissions: aws.String(ci.Permissions),
+			}
+		}
+	}
+	// Sort for predictability
+	keys := make([]string, 0, len(req.Tags))
+	for k := range req.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		input.Tags = append(input.Tags, &efs.Tag{Key: aws.String(k), Value: aws.String(req.Tags[k])})
+	}
+
+	out, err := c.api.CreateAccessPointWithContext(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.AccessPointId), nil
+}
+
+// DeleteAccessPoint implements Client.
+func (c *awsClient) DeleteAccessPoint(ctx context.Context, accessPointID string) error {
+	_, err := c.api.DeleteAccessPointWithContext(ctx, &efs.DeleteAccessPointInput{AccessPointId: aws.String(accessPointID)})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == efs.ErrCodeAccessPointNotFound {
+		// Already gone. That's fine.
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package efs
+
+import (
+	"context"
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/efs/efsiface"
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	// TODO: pkg/client/fake is deprecated, replace with pkg/envtest
+	// nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var ctx = context.TODO()
+
+// stubEFS records the inputs it is called with. Unimplemented methods panic via the nil
+// embedded interface.
+type stubEFS struct {
+	efsiface.EFSAPI
+	createInput *efs.CreateAccessPointInput
+	deleteInput *efs.DeleteAccessPointInput
+	deleteErr   error
+}
+
+func (s *stubEFS) CreateAccessPointWithContext(
+	_ aws.Context, in *efs.CreateAccessPointInput, _ ...request.Option) (*efs.CreateAccessPointOutput, error) {
+	s.createInput = in
+	return &efs.CreateAccessPointOutput{AccessPointId: aws.String("fsap-0123456789abcdef")}, nil
+}
+
+func (s *stubEFS) DeleteAccessPointWithContext(
+	_ aws.Context, in *efs.DeleteAccessPointInput, _ ...request.Option) (*efs.DeleteAccessPointOutput, error) {
+	s.deleteInput = in
+	return &efs.DeleteAccessPointOutput{}, s.deleteErr
+}
+
+func TestCreateAccessPoint(t *testing.T) {
+	stub := &stubEFS{}
+	c := &awsClient{api: stub}
+	apid, err := c.CreateAccessPoint(ctx, AccessPointRequest{
+		ClientToken:  "token",
+		FileSystemID: "fs-123abc",
+		Spec: awsefsv1alpha1.AccessPointSpec{
+			PosixUser: awsefsv1alpha1.PosixUser{UID: 1000, GID: 2000, SecondaryGIDs: []int64{3000}},
+			RootDirectory: &awsefsv1alpha1.RootDirectory{
+				Path: "/data",
+				CreationInfo: &awsefsv1alpha1.CreationInfo{
+					OwnerUID:    1000,
+					OwnerGID:    2000,
+					Permissions: "0750",
+				},
+			},
+		},
+		Tags: map[string]string{"b": "2", "a": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apid != "fsap-0123456789abcdef" {
+		t.Fatalf("Unexpected access point ID %q", apid)
+	}
+	expected := &efs.CreateAccessPointInput{
+		ClientToken:  aws.String("token"),
+		FileSystemId: aws.String("fs-123abc"),
+		PosixUser: &efs.PosixUser{
+			Uid:           aws.Int64(1000),
+			Gid:           aws.Int64(2000),
+			SecondaryGids: aws.Int64Slice([]int64{3000}),
+		},
+		RootDirectory: &efs.RootDirectory{
+			Path: aws.String("/data"),
+			CreationInfo: &efs.CreationInfo{
+				OwnerUid:    aws.Int64(1000),
+				OwnerGid:    aws.Int64(2000),
+				Permissions: aws.String("0750"),
+			},
+		},
+		Tags: []*efs.Tag{
+			{Key: aws.String("a"), Value: aws.String("1")},
+			{Key: aws.String("b"), Value: aws.String("2")},
+		},
+	}
+	if diff := cmp.Diff(expected, stub.createInput); diff != "" {
+		t.Fatal("Inputs differ: -expected, +actual\n", diff)
+	}
+
+	// Minimal request: no root directory, secondary GIDs, or tags
+	if _, err = c.CreateAccessPoint(ctx, AccessPointRequest{ClientToken: "t", FileSystemID: "fs-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if in := stub.createInput; in.RootDirectory != nil || in.PosixUser.SecondaryGids != nil || in.Tags != nil {
+		t.Fatalf("Expected no root directory, secondary GIDs, or tags but got %v", in)
+	}
+}
+
+func TestDeleteAccessPoint(t *testing.T) {
+	stub := &stubEFS{}
+	c := &awsClient{api: stub}
+	if err := c.DeleteAccessPoint(ctx, "fsap-1"); err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(stub.deleteInput.AccessPointId) != "fsap-1" {
+		t.Fatalf("Unexpected input %v", stub.deleteInput)
+	}
+
+	// Not found is fine
+	stub.deleteErr = awserr.New(efs.ErrCodeAccessPointNotFound, "gone", nil)
+	if err := c.DeleteAccessPoint(ctx, "fsap-1"); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	// Anything else isn't
+	stub.deleteErr = awserr.New(efs.ErrCodeAccessPointLimitExceeded, "nope", nil)
+	if err := c.DeleteAccessPoint(ctx, "fsap-1"); err != stub.deleteErr {
+		t.Fatalf("Expected %v but got %v", stub.deleteErr, err)
+	}
+}
+
+func TestDiscoverRegion(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := configv1.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: infrastructureName},
+	}
+
+	// No Infrastructure at all
+	if _, err := DiscoverRegion(ctx, fake.NewFakeClientWithScheme(sch)); err == nil {
+		t.Fatal("Expected an error with no Infrastructure")
+	}
+	// No AWS platform status
+	if _, err := DiscoverRegion(ctx, fake.NewFakeClientWithScheme(sch, infra.DeepCopy())); err == nil {
+		t.Fatal("Expected an error with no AWS platform status")
+	}
+	// Green path
+	infra.Status.PlatformStatus = &configv1.PlatformStatus{
+		Type: configv1.AWSPlatformType,
+		AWS:  &configv1.AWSPlatformStatus{Region: "us-east-2"},
+	}
+	region, err := DiscoverRegion(ctx, fake.NewFakeClientWithScheme(sch, infra))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region != "us-east-2" {
+		t.Fatalf("Expected us-east-2 but got %q", region)
+	}
+}
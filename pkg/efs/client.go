@@ -0,0 +1,34 @@
+package efs
+
+/**
+A thin abstraction over the parts of the AWS EFS API used by the operator. Consumers should only
+ever deal with the `Client` interface, so that it can be replaced by an in-memory fake in tests.
+*/
+
+import (
+	"context"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+)
+
+// AccessPointRequest describes an access point to be created.
+type AccessPointRequest struct {
+	// ClientToken makes the creation idempotent: repeated requests with the same token result in
+	// the same access point. Use something unique and stable, like the UID of the SharedVolume.
+	ClientToken string
+	// FileSystemID is the EFS volume in which to create the access point.
+	FileSystemID string
+	// Spec describes the POSIX user, root directory, etc. of the access point.
+	Spec awsefsv1alpha1.AccessPointSpec
+	// Tags are applied to the access point, to make it easier to trace back to its SharedVolume.
+	Tags map[string]string
+}
+
+// Client is the interface to the EFS API.
+type Client interface {
+	// CreateAccessPoint creates an access point as described by `req`, returning its ID.
+	CreateAccessPoint(ctx context.Context, req AccessPointRequest) (string, error)
+	// DeleteAccessPoint deletes the access point with the given ID. It is not an error if the
+	// access point does not exist.
+	DeleteAccessPoint(ctx context.Context, accessPointID string) error
+}
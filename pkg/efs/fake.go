@@ -0,0 +1,61 @@
+package efs
+
+// In-memory Client implementation for use in tests.
+
+import (
+	"context"
+	"fmt"
+)
+
+// FakeClient is an in-memory implementation of Client. It honors ClientToken idempotency like
+// the real thing. Set CreateError or DeleteError to make the respective calls fail.
+type FakeClient struct {
+	// AccessPoints maps the ID of each existing access point to the request that created it.
+	AccessPoints map[string]AccessPointRequest
+	// CreateError, if non-nil, is returned by CreateAccessPoint, which then does nothing else.
+	CreateError error
+	// DeleteError, if non-nil, is returned by DeleteAccessPoint, which then does nothing else.
+	DeleteError error
+	// Private map of ClientToken to access point ID
+	byToken map[string]string
+	// Private counter used to generate access point IDs
+	numCreated int
+}
+
+// blank assignment to verify that FakeClient implements Client
+var _ Client = &FakeClient{}
+
+// NewFakeClient returns a FakeClient with no access points.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		AccessPoints: make(map[string]AccessPointRequest),
+		byToken:      make(map[string]string),
+	}
+}
+
+// CreateAccessPoint implements Client.
+func (f *FakeClient) CreateAccessPoint(_ context.Context, req AccessPointRequest) (string, error) {
+	if f.CreateError != nil {
+		return "", f.CreateError
+	}
+	if id, ok := f.byToken[req.ClientToken]; ok {
+		return id, nil
+	}
+	f.numCreated++
+	id := fmt.Sprintf("fsap-%017x", f.numCreated)
+	f.AccessPoints[id] = req
+	f.byToken[req.ClientToken] = id
+	return id, nil
+}
+
+// DeleteAccessPoint implements Client.
+func (f *FakeClient) DeleteAccessPoint(_ context.Context, accessPointID string) error {
+	if f.DeleteError != nil {
+		return f.DeleteError
+	}
+	if req, ok := f.AccessPoints[accessPointID]; ok {
+		delete(f.byToken, req.ClientToken)
+		delete(f.AccessPoints, accessPointID)
+	}
+	return nil
+}
@@ -29,3 +29,6 @@ var NotFound error = clientError{reason: metav1.StatusReasonNotFound}
 
 // AlreadyExists stub API response
 var AlreadyExists error = clientError{reason: metav1.StatusReasonAlreadyExists}
+
+// Invalid stub API response
+var Invalid error = clientError{reason: metav1.StatusReasonInvalid}
@@ -5,6 +5,7 @@
 package fixtures
 
 import (
+	context "context"
 	reflect "reflect"
 
 	logr "github.com/go-logr/logr"
@@ -12,6 +13,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -39,31 +41,31 @@ func (m *MockEnsurable) EXPECT() *MockEnsurableMockRecorder {
 }
 
 // Delete mocks base method.
-func (m *MockEnsurable) Delete(arg0 logr.Logger, arg1 client.Client) error {
+func (m *MockEnsurable) Delete(arg0 context.Context, arg1 logr.Logger, arg2 client.Client) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockEnsurableMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEnsurableMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockEnsurable)(nil).Delete), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockEnsurable)(nil).Delete), arg0, arg1, arg2)
 }
 
 // Ensure mocks base method.
-func (m *MockEnsurable) Ensure(arg0 logr.Logger, arg1 client.Client) error {
+func (m *MockEnsurable) Ensure(arg0 context.Context, arg1 logr.Logger, arg2 client.Client) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Ensure", arg0, arg1)
+	ret := m.ctrl.Call(m, "Ensure", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Ensure indicates an expected call of Ensure.
-func (mr *MockEnsurableMockRecorder) Ensure(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEnsurableMockRecorder) Ensure(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ensure", reflect.TypeOf((*MockEnsurable)(nil).Ensure), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ensure", reflect.TypeOf((*MockEnsurable)(nil).Ensure), arg0, arg1, arg2)
 }
 
 // GetNamespacedName mocks base method.
@@ -81,10 +83,10 @@ func (mr *MockEnsurableMockRecorder) GetNamespacedName() *gomock.Call {
 }
 
 // GetType mocks base method.
-func (m *MockEnsurable) GetType() runtime.Object {
+func (m *MockEnsurable) GetType() client.Object {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetType")
-	ret0, _ := ret[0].(runtime.Object)
+	ret0, _ := ret[0].(client.Object)
 	return ret0
 }
 
@@ -94,6 +96,18 @@ func (mr *MockEnsurableMockRecorder) GetType() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetType", reflect.TypeOf((*MockEnsurable)(nil).GetType))
 }
 
+// SetEventRecorder mocks base method.
+func (m *MockEnsurable) SetEventRecorder(arg0 record.EventRecorder, arg1 runtime.Object) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEventRecorder", arg0, arg1)
+}
+
+// SetEventRecorder indicates an expected call of SetEventRecorder.
+func (mr *MockEnsurableMockRecorder) SetEventRecorder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEventRecorder", reflect.TypeOf((*MockEnsurable)(nil).SetEventRecorder), arg0, arg1)
+}
+
 // SetOwner mocks base method.
 func (m *MockEnsurable) SetOwner(arg0 *v1.OwnerReference) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,92 @@
+package health
+
+/**
+Building blocks for the manager's liveness (/healthz) and readiness (/readyz) checks. The checks
+themselves are wired up in cmd/manager.
+*/
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// CacheSyncTimeout bounds how long the CachesSynced check waits for the caches, so it answers well
+// within the probe's own timeout.
+var CacheSyncTimeout = 500 * time.Millisecond
+
+// Flag is a readiness check that fails, with its message, until it is Set.
+type Flag struct {
+	set     int32
+	message string
+}
+
+// NewFlag returns an unset Flag whose check fails with `message`.
+func NewFlag(message string) *Flag {
+	return &Flag{message: message}
+}
+
+// Set makes the Flag's check pass from now on.
+func (f *Flag) Set() {
+	atomic.StoreInt32(&f.set, 1)
+}
+
+// IsSet returns whether Set has been called.
+func (f *Flag) IsSet() bool {
+	return atomic.LoadInt32(&f.set) == 1
+}
+
+// Check is the Flag's healthz.Checker.
+func (f *Flag) Check(_ *http.Request) error {
+	if !f.IsSet() {
+		return errors.New(f.message)
+	}
+	return nil
+}
+
+// LeaderOnly returns a check that only applies `check` once `elected` is closed, i.e. once this
+// replica has become the leader. Until then it passes, so that replicas standing by can serve the
+// webhook; what `check` reflects is the leader's job.
+func LeaderOnly(elected <-chan struct{}, check healthz.Checker) healthz.Checker {
+	return func(req *http.Request) error {
+		select {
+		case <-elected:
+			return check(req)
+		default:
+			return nil
+		}
+	}
+}
+
+// Once returns a check that calls `discover` until it succeeds, and passes from then on without
+// calling it again.
+func Once(discover func(context.Context) error) healthz.Checker {
+	flag := &Flag{}
+	return func(req *http.Request) error {
+		if flag.IsSet() {
+			return nil
+		}
+		if err := discover(req.Context()); err != nil {
+			return err
+		}
+		flag.Set()
+		return nil
+	}
+}
+
+// CachesSynced returns a check that passes if the informers' caches have started and synced.
+func CachesSynced(informers cache.Informers) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), CacheSyncTimeout)
+		defer cancel()
+		if !informers.WaitForCacheSync(ctx) {
+			return errors.New("caches not synced")
+		}
+		return nil
+	}
+}
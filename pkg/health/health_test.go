@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+)
+
+func TestFlag(t *testing.T) {
+	f := NewFlag("not yet")
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	if err := f.Check(req); err == nil || err.Error() != "not yet" {
+		t.Fatalf("Expected the unset flag to fail with its message, but got %v", err)
+	}
+	f.Set()
+	if err := f.Check(req); err != nil {
+		t.Fatalf("Expected the set flag to pass, but got %v", err)
+	}
+}
+
+func TestLeaderOnly(t *testing.T) {
+	elected := make(chan struct{})
+	f := NewFlag("not yet")
+	check := LeaderOnly(elected, f.Check)
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	// Standing by, the check doesn't apply
+	if err := check(req); err != nil {
+		t.Fatalf("Expected a pass before election, but got %v", err)
+	}
+	// Once elected, it does
+	close(elected)
+	if err := check(req); err == nil {
+		t.Fatal("Expected a failure after election")
+	}
+	f.Set()
+	if err := check(req); err != nil {
+		t.Fatalf("Expected a pass, but got %v", err)
+	}
+}
+
+func TestOnce(t *testing.T) {
+	calls := 0
+	theError := errors.New("not found")
+	check := Once(func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return theError
+		}
+		return nil
+	})
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	for i := 0; i < 2; i++ {
+		if err := check(req); err != theError {
+			t.Fatalf("Expected %v, but got %v", theError, err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := check(req); err != nil {
+			t.Fatalf("Expected a pass, but got %v", err)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("Expected discovery to stop after succeeding, but it was called %d times", calls)
+	}
+}
+
+func TestCachesSynced(t *testing.T) {
+	synced := false
+	check := CachesSynced(&informertest.FakeInformers{Synced: &synced})
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	if err := check(req); err == nil {
+		t.Fatal("Expected a failure while the caches aren't synced")
+	}
+	synced = true
+	if err := check(req); err != nil {
+		t.Fatalf("Expected a pass, but got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package metrics
+
+/**
+Operator-specific Prometheus metrics. These are registered with controller-runtime's registry, so
+they're served from the same endpoint as the stock controller-runtime metrics.
+*/
+
+import (
+	"context"
+	"time"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "aws_efs_operator"
+
+var (
+	// ReconcileDuration measures how long each Reconcile takes, per controller.
+	ReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "reconcile_duration_seconds",
+			Help:      "Time taken by each Reconcile, per controller.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"controller"},
+	)
+
+	// EnsureActions counts the changes made to the server by Ensurables, per resource kind and
+	// action (create, update, delete).
+	EnsureActions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ensure_actions_total",
+			Help:      "Resources created, updated, or deleted by the operator, per kind and action.",
+		},
+		[]string{"kind", "action"},
+	)
+
+	// SpecReverts counts edits to SharedVolume specs that the operator reverted, per namespace.
+	SpecReverts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sharedvolume_spec_reverts_total",
+			Help:      "Changes to SharedVolume specs reverted by the operator, per namespace.",
+		},
+		[]string{"namespace"},
+	)
+
+	// StaticsDriftCorrections counts the times a static resource was found to have deviated from
+	// its definition (or to have been deleted) and was restored, per resource kind.
+	StaticsDriftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "statics_drift_corrections_total",
+			Help:      "Static resources restored after deviating from their definitions, per kind.",
+		},
+		[]string{"kind"},
+	)
+
+	// StaticsDegraded is 1 if the operator's most recent pass over the static resources failed,
+	// and 0 otherwise.
+	StaticsDegraded = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "statics_degraded",
+			Help:      "Whether the operator's most recent pass over the static resources failed.",
+		},
+	)
+
+	sharedVolumesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "sharedvolumes"),
+		"Number of SharedVolumes, per namespace and phase.",
+		[]string{"namespace", "phase"},
+		nil,
+	)
+
+	log = logf.Log.WithName("metrics")
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(ReconcileDuration, EnsureActions, SpecReverts, StaticsDriftCorrections, StaticsDegraded)
+}
+
+// ObserveReconcile records the time since `start` in ReconcileDuration for `controller`. It's
+// meant to be deferred, with `start` as time.Now(), at the top of Reconcile.
+func ObserveReconcile(controller string, start time.Time) {
+	ReconcileDuration.WithLabelValues(controller).Observe(time.Since(start).Seconds())
+}
+
+// CountEnsureActions returns an EnsurableImpl OnChange hook counting, in EnsureActions, the
+// changes made to resources of the given `kind`.
+func CountEnsureActions(kind string) func(action string) {
+	return func(action string) {
+		EnsureActions.WithLabelValues(kind, action).Inc()
+	}
+}
+
+// sharedVolumeCollector is a prometheus.Collector reporting the number of SharedVolumes per
+// namespace and phase. It counts them afresh on each scrape, so the numbers can't drift from
+// reality, and SharedVolumes that go away simply stop being reported.
+type sharedVolumeCollector struct {
+	reader client.Reader
+}
+
+// RegisterSharedVolumeCollector registers a collector reporting the number of SharedVolumes per
+// namespace and phase. It lists the SharedVolumes via `reader`, which should be backed by the
+// manager's cache so scrapes don't hit the API server.
+func RegisterSharedVolumeCollector(reader client.Reader) error {
+	return crmetrics.Registry.Register(&sharedVolumeCollector{reader: reader})
+}
+
+// Describe implements prometheus.Collector.
+func (c *sharedVolumeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sharedVolumesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *sharedVolumeCollector) Collect(ch chan<- prometheus.Metric) {
+	svList := &awsefsv1alpha1.SharedVolumeList{}
+	if err := c.reader.List(context.TODO(), svList); err != nil {
+		log.Error(err, "Failed to list SharedVolumes")
+		ch <- prometheus.NewInvalidMetric(sharedVolumesDesc, err)
+		return
+	}
+	type key struct {
+		namespace string
+		phase     awsefsv1alpha1.SharedVolumePhase
+	}
+	counts := make(map[key]int)
+	for _, sv := range svList.Items {
+		counts[key{sv.Namespace, sv.Status.Phase}]++
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			sharedVolumesDesc, prometheus.GaugeValue, float64(count), k.namespace, string(k.phase))
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func mkSharedVolume(namespace, name string, phase awsefsv1alpha1.SharedVolumePhase) *awsefsv1alpha1.SharedVolume {
+	return &awsefsv1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status:     awsefsv1alpha1.SharedVolumeStatus{Phase: phase},
+	}
+}
+
+// TestSharedVolumeCollector checks that SharedVolumes are counted per namespace and phase.
+func TestSharedVolumeCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := awsefsv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewFakeClientWithScheme(scheme,
+		mkSharedVolume("proj1", "sv1", awsefsv1alpha1.SharedVolumeReady),
+		mkSharedVolume("proj1", "sv2", awsefsv1alpha1.SharedVolumeReady),
+		mkSharedVolume("proj1", "sv3", awsefsv1alpha1.SharedVolumePending),
+		mkSharedVolume("proj2", "sv1", awsefsv1alpha1.SharedVolumeFailed),
+	)
+
+	expected := `
+# HELP aws_efs_operator_sharedvolumes Number of SharedVolumes, per namespace and phase.
+# TYPE aws_efs_operator_sharedvolumes gauge
+aws_efs_operator_sharedvolumes{namespace="proj1",phase="Pending"} 1
+aws_efs_operator_sharedvolumes{namespace="proj1",phase="Ready"} 2
+aws_efs_operator_sharedvolumes{namespace="proj2",phase="Failed"} 1
+`
+	if err := testutil.CollectAndCompare(&sharedVolumeCollector{reader: client}, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestObserveReconcile checks that reconcile durations are recorded per controller.
+func TestObserveReconcile(t *testing.T) {
+	ObserveReconcile("test-controller", time.Now().Add(-time.Second))
+	ObserveReconcile("test-controller", time.Now())
+
+	metric := &dto.Metric{}
+	if err := ReconcileDuration.WithLabelValues("test-controller").(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if count := metric.GetHistogram().GetSampleCount(); count != 2 {
+		t.Fatalf("Expected 2 observations but got %d", count)
+	}
+	if sum := metric.GetHistogram().GetSampleSum(); sum < 1 {
+		t.Fatalf("Expected the observations to add up to at least a second but got %v", sum)
+	}
+}
+
+// TestCountEnsureActions checks that the hook counts actions per kind.
+func TestCountEnsureActions(t *testing.T) {
+	count := func(action string) float64 {
+		return testutil.ToFloat64(EnsureActions.WithLabelValues("TestKind", action))
+	}
+	hook := CountEnsureActions("TestKind")
+	hook("create")
+	hook("update")
+	hook("update")
+	if c, u, d := count("create"), count("update"), count("delete"); c != 1 || u != 2 || d != 0 {
+		t.Fatalf("Expected 1 create, 2 updates and no deletes but got %v, %v and %v", c, u, d)
+	}
+}
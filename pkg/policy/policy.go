@@ -0,0 +1,70 @@
+package policy
+
+/**
+Enforcement of SharedVolumePolicies, shared by the sharedvolume controller and the validating
+webhook. Namespaces not selected by any policy are unrestricted. SharedVolumes in a namespace
+selected by one or more policies may only use a file system and access point allowed by at least
+one of them.
+*/
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Check decides whether SharedVolumes in the `namespace` may use the file system `fsid` and the
+// access point `apid`, which is empty for an access point the operator is to provision. If not,
+// it returns a message, suitable for showing to the user, explaining why. An error means the
+// policies couldn't be evaluated, in which case the caller should not assume the volume is allowed.
+func Check(ctx context.Context, reader client.Reader, namespace, fsid, apid string) (string, error) {
+	policies := &awsefsv1alpha1.SharedVolumePolicyList{}
+	if err := reader.List(ctx, policies); err != nil {
+		return "", err
+	}
+	if len(policies.Items) == 0 {
+		// Nothing to enforce. Don't bother looking up the namespace.
+		return "", nil
+	}
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return "", err
+	}
+
+	applicable := []string{}
+	for i := range policies.Items {
+		p := &policies.Items[i]
+		applies, err := p.AppliesTo(ns)
+		if err != nil {
+			return "", fmt.Errorf("invalid namespaceSelector in SharedVolumePolicy %s: %v", p.Name, err)
+		}
+		if !applies {
+			continue
+		}
+		if p.Allows(fsid, apid) {
+			return "", nil
+		}
+		applicable = append(applicable, p.Name)
+	}
+	if len(applicable) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(applicable)
+	what := fmt.Sprintf("access point %s", apid)
+	if apid == "" {
+		what = "an access point provisioned by the operator"
+	}
+	who := fmt.Sprintf("SharedVolumePolicy %s does", applicable[0])
+	if len(applicable) > 1 {
+		who = fmt.Sprintf("SharedVolumePolicies %s do", strings.Join(applicable, ", "))
+	}
+	return fmt.Sprintf("%s not allow namespace %s to use file system %s with %s", who, namespace, fsid, what), nil
+}
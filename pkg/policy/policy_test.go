@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	// TODO: pkg/client/fake is deprecated, replace with pkg/envtest
+	// nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	fs1 = "fs-000001"
+	fs2 = "fs-000002"
+	ap1 = "fsap-1111111d"
+	ap2 = "fsap-2222222e"
+)
+
+func mkNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func mkPolicy(name string, spec awsefsv1alpha1.SharedVolumePolicySpec) *awsefsv1alpha1.SharedVolumePolicy {
+	return &awsefsv1alpha1.SharedVolumePolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	sch := runtime.NewScheme()
+	if err := scheme.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	if err := awsefsv1alpha1.SchemeBuilder.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+func TestCheckNoPolicies(t *testing.T) {
+	// Without any policies, anything goes, and we don't even need the namespace to exist.
+	c := fake.NewFakeClientWithScheme(newScheme(t))
+	if msg, err := Check(context.TODO(), c, "proj1", fs1, ap1); msg != "" || err != nil {
+		t.Fatalf("Expected no violation, no error; got\nmessage: %q\nerr: %v", msg, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(newScheme(t),
+		mkNamespace("proj1", nil),
+		mkNamespace("proj2", map[string]string{"team": "b"}),
+		mkNamespace("proj3", map[string]string{"team": "c"}),
+		mkNamespace("other", nil),
+		// proj1 may use any access point on fs1
+		mkPolicy("team-a", awsefsv1alpha1.SharedVolumePolicySpec{
+			Namespaces: []string{"proj1"},
+			Allowed:    []awsefsv1alpha1.AllowedVolume{{FileSystemID: fs1}},
+		}),
+		// team b and c may use ap2 on fs2...
+		mkPolicy("teams-b-c", awsefsv1alpha1.SharedVolumePolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"b", "c"}},
+				},
+			},
+			Allowed: []awsefsv1alpha1.AllowedVolume{{FileSystemID: fs2, AccessPointIDs: []string{ap2}}},
+		}),
+		// ...and team c may also use ap1 on fs2
+		mkPolicy("team-c", awsefsv1alpha1.SharedVolumePolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "c"}},
+			Allowed:           []awsefsv1alpha1.AllowedVolume{{FileSystemID: fs2, AccessPointIDs: []string{ap1}}},
+		}),
+	)
+
+	tests := []struct {
+		namespace string
+		fsid      string
+		apid      string
+		expected  string
+	}{
+		{"proj1", fs1, ap1, ""},
+		{"proj1", fs1, "", ""},
+		{"proj1", fs2, ap2, "SharedVolumePolicy team-a does not allow namespace proj1 to use file system " +
+			"fs-000002 with access point fsap-2222222e"},
+		{"proj2", fs2, ap2, ""},
+		{"proj2", fs2, ap1, "SharedVolumePolicy teams-b-c does not allow namespace proj2 to use file system " +
+			"fs-000002 with access point fsap-1111111d"},
+		{"proj2", fs2, "", "SharedVolumePolicy teams-b-c does not allow namespace proj2 to use file system " +
+			"fs-000002 with an access point provisioned by the operator"},
+		{"proj3", fs2, ap1, ""},
+		{"proj3", fs2, ap2, ""},
+		{"proj3", fs1, ap1, "SharedVolumePolicies team-c, teams-b-c do not allow namespace proj3 to use file system " +
+			"fs-000001 with access point fsap-1111111d"},
+		// Not selected by any policy
+		{"other", fs2, ap1, ""},
+	}
+	for _, tt := range tests {
+		msg, err := Check(context.TODO(), c, tt.namespace, tt.fsid, tt.apid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg != tt.expected {
+			t.Fatalf("%s %s %s: expected\n%q\nbut got\n%q", tt.namespace, tt.fsid, tt.apid, tt.expected, msg)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(newScheme(t),
+		mkNamespace("proj1", nil),
+		mkPolicy("everyone", awsefsv1alpha1.SharedVolumePolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{},
+		}),
+	)
+	// An empty selector selects every namespace, but the namespace has to exist.
+	if _, err := Check(context.TODO(), c, "missing", fs1, ap1); err == nil {
+		t.Fatal("Expected an error for a missing namespace")
+	}
+	if msg, err := Check(context.TODO(), c, "proj1", fs1, ap1); msg == "" || err != nil {
+		t.Fatalf("Expected a violation, no error; got\nmessage: %q\nerr: %v", msg, err)
+	}
+
+	// A malformed selector is an error, rather than being ignored.
+	c = fake.NewFakeClientWithScheme(newScheme(t),
+		mkNamespace("proj1", nil),
+		mkPolicy("broken", awsefsv1alpha1.SharedVolumePolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Sort of"}},
+			},
+		}),
+	)
+	if _, err := Check(context.TODO(), c, "proj1", fs1, ap1); err == nil {
+		t.Fatal("Expected an error for a malformed selector")
+	}
+}
@@ -2,8 +2,15 @@ package test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
 
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -50,7 +57,7 @@ func clientOverride(behavior []error, numCalls int) error {
 }
 
 // Get overrides the fake client's Get, conditionally bypassing it and returning an error instead.
-func (f *FakeClientWithCustomErrors) Get(ctx context.Context, key crclient.ObjectKey, obj runtime.Object) error {
+func (f *FakeClientWithCustomErrors) Get(ctx context.Context, key crclient.ObjectKey, obj crclient.Object) error {
 	// Always increment the call count, but not until we're done.
 	defer func() { f.numGetCalls++ }()
 	if err := clientOverride(f.GetBehavior, f.numGetCalls); err != nil {
@@ -61,7 +68,7 @@ func (f *FakeClientWithCustomErrors) Get(ctx context.Context, key crclient.Objec
 }
 
 // Delete overrides the fake client's Delete, conditionally bypassing it and returning an error instead.
-func (f *FakeClientWithCustomErrors) Delete(ctx context.Context, obj runtime.Object, opts ...crclient.DeleteOption) error {
+func (f *FakeClientWithCustomErrors) Delete(ctx context.Context, obj crclient.Object, opts ...crclient.DeleteOption) error {
 	// Always increment the call count, but not until we're done.
 	defer func() { f.numDeleteCalls++ }()
 	if err := clientOverride(f.DeleteBehavior, f.numDeleteCalls); err != nil {
@@ -72,7 +79,7 @@ func (f *FakeClientWithCustomErrors) Delete(ctx context.Context, obj runtime.Obj
 }
 
 // Update overrides the fake client's Update, conditionally bypassing it and returning an error instead.
-func (f *FakeClientWithCustomErrors) Update(ctx context.Context, obj runtime.Object, opts ...crclient.UpdateOption) error {
+func (f *FakeClientWithCustomErrors) Update(ctx context.Context, obj crclient.Object, opts ...crclient.UpdateOption) error {
 	// Always increment the call count, but not until we're done.
 	defer func() { f.numUpdateCalls++ }()
 	if err := clientOverride(f.UpdateBehavior, f.numUpdateCalls); err != nil {
@@ -81,3 +88,83 @@ func (f *FakeClientWithCustomErrors) Update(ctx context.Context, obj runtime.Obj
 	// Otherwise run the real Update
 	return f.Client.Update(ctx, obj, opts...)
 }
+
+// ApplyingClient makes do for server-side apply, which the fake client doesn't support: an Apply
+// Patch creates the object if it doesn't exist, or else strategic-merges it into what's there,
+// which, like apply, leaves alone the fields it doesn't mention. Fields it mentioned the last time
+// and no longer does are removed, as if it owned them, the way kubectl's client-side apply works
+// out what to delete. As on a real server, the object isn't written, and its ResourceVersion
+// doesn't change, if that makes no difference.
+type ApplyingClient struct {
+	// The "Real" fake client
+	crclient.Client
+	// What was last applied, by type and key
+	applied map[string][]byte
+	mutex   sync.Mutex
+}
+
+// Patch overrides the fake client's Patch, emulating Apply Patches and passing others through.
+func (a *ApplyingClient) Patch(ctx context.Context, obj crclient.Object, patch crclient.Patch, opts ...crclient.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return a.Client.Patch(ctx, obj, patch, opts...)
+	}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	key := crclient.ObjectKeyFromObject(obj)
+	appliedKey := fmt.Sprintf("%T %s", obj, key)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.applied == nil {
+		a.applied = make(map[string][]byte)
+	}
+	found := newLike(obj)
+	if err := a.Client.Get(ctx, key, found); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if err := a.Client.Create(ctx, obj); err != nil {
+			return err
+		}
+		a.applied[appliedKey] = data
+		return nil
+	}
+	foundJSON, err := json.Marshal(found)
+	if err != nil {
+		return err
+	}
+	patchMeta, err := strategicpatch.NewPatchMetaFromStruct(found)
+	if err != nil {
+		return err
+	}
+	diff, err := strategicpatch.CreateThreeWayMergePatch(a.applied[appliedKey], data, foundJSON, patchMeta, true)
+	if err != nil {
+		return err
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatchUsingLookupPatchMeta(foundJSON, diff, patchMeta)
+	if err != nil {
+		return err
+	}
+	merged := newLike(obj)
+	if err := json.Unmarshal(mergedJSON, merged); err != nil {
+		return err
+	}
+	// Compare content only: the patch carries TypeMeta, which what we got may lack
+	merged.GetObjectKind().SetGroupVersionKind(found.GetObjectKind().GroupVersionKind())
+	if !equality.Semantic.DeepEqual(found, merged) {
+		if err := a.Client.Update(ctx, merged); err != nil {
+			return err
+		}
+	}
+	a.applied[appliedKey] = data
+	// Hand back what the server has, like a real Patch
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(newLike(obj)).Elem())
+	return a.Client.Get(ctx, key, obj)
+}
+
+// newLike returns a new, empty object of the same type as `obj`. (The fake client's Get doesn't
+// clear fields the server doesn't have.)
+func newLike(obj crclient.Object) crclient.Object {
+	return reflect.New(reflect.TypeOf(obj).Elem()).Interface().(crclient.Object)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/yaml"
 )
 
@@ -39,3 +40,23 @@ func DoDiff(t *testing.T, expected, actual runtime.Object, expectLabel bool) {
 		t.Fatalf("expectLabel was %v but DoICare returned %v", expectLabel, doICare)
 	}
 }
+
+// NewFakeRecorder returns an EventRecorder whose Events can be inspected via DrainEvents. It has
+// room for plenty of Events, because it blocks when full.
+func NewFakeRecorder() *record.FakeRecorder {
+	return record.NewFakeRecorder(1000)
+}
+
+// DrainEvents returns the Events recorded by the `recorder` since the last call, in order, each
+// formatted as "$type $reason $message".
+func DrainEvents(recorder *record.FakeRecorder) []string {
+	events := []string{}
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
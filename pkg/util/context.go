@@ -0,0 +1,17 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// ReconcileTimeout bounds how long a reconcile may take, so a hung call to the API server (or to
+// AWS) doesn't wedge a worker forever. It must be set before the controllers are added.
+var ReconcileTimeout = 2 * time.Minute
+
+// ReconcileContext returns a Context for a single reconcile, derived from `parent` (which the
+// manager cancels when it stops) and limited to ReconcileTimeout. The caller must call the
+// CancelFunc when done.
+func ReconcileContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, ReconcileTimeout)
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReconcileContext(t *testing.T) {
+	// Bounded by ReconcileTimeout
+	ctx, cancel := ReconcileContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("Expected a deadline")
+	}
+	if remaining := time.Until(deadline); remaining > ReconcileTimeout {
+		t.Fatalf("Expected the deadline within %v, but it's %v away", ReconcileTimeout, remaining)
+	}
+
+	// Canceling the parent cancels the child
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = ReconcileContext(parent)
+	defer cancel()
+	cancelParent()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the reconcile context to be canceled with its parent")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("Expected %v, got %v", context.Canceled, ctx.Err())
+	}
+}
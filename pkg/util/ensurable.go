@@ -9,50 +9,98 @@ and updated to a canonical representation if they deviate from it.
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Ensurable provides helpers to allow ensuring the existence and state of a resource.
 type Ensurable interface {
-	// GetType returns a unique, empty runtime.Object of the specific type of the ensurable resource.
-	GetType() runtime.Object
+	// GetType returns a unique, empty client.Object of the specific type of the ensurable resource.
+	GetType() crclient.Object
 	// GetNamespacedName returns the `NamespacedName` for the resource. This can be used to identify
 	// the Ensurable associated with a `reconcile.Request`.
 	GetNamespacedName() types.NamespacedName
 	// SetOwner sets the OwnerReferences field to a list of one element, the argument.
 	SetOwner(*metav1.OwnerReference)
+	// SetEventRecorder arranges for Ensure and Delete to emit Events via the EventRecorder when they
+	// create, update, or delete the resource, or fail to. The Events are recorded against the
+	// runtime.Object, or against the resource itself if that's nil.
+	SetEventRecorder(record.EventRecorder, runtime.Object)
 	// Ensure creates an Ensurable resource if it doesn't already exist, or updates it if it exists
 	// and differs from the gold standard.
-	Ensure(logr.Logger, crclient.Client) error
+	Ensure(context.Context, logr.Logger, crclient.Client) error
 	// Delete makes sure the resource represented by the Ensurable is gone.
-	Delete(logr.Logger, crclient.Client) error
+	Delete(context.Context, logr.Logger, crclient.Client) error
 }
 
-// EnsurableImpl provides the implementation of the Ensurable interface.
+// EnsurableImpl provides the implementation of the Ensurable interface. It's safe for concurrent
+// use: Ensure, Delete and the setters are serialized, since they share what's cached from the
+// server.
 type EnsurableImpl struct {
-	ObjType        runtime.Object
+	ObjType        crclient.Object
 	NamespacedName types.NamespacedName
-	Definition     runtime.Object
+	Definition     crclient.Object
 	EqualFunc      func(local, server runtime.Object) bool
-	owner          *metav1.OwnerReference
-	latestVersion  runtime.Object
+	// OnDrift, if set, is called when Ensure restores a resource that deviated from its
+	// definition, or that was deleted after we had created or found it.
+	OnDrift func()
+	// OnChange, if set, is called with the action (ActionCreate, ActionUpdate or ActionDelete)
+	// whenever Ensure or Delete successfully changes the resource on the server.
+	OnChange func(action string)
+	// Recreate, if set, makes Ensure delete and recreate a resource that deviates from its
+	// definition, rather than updating it. Use it for resources, like StorageClasses, whose content
+	// can't be changed.
+	Recreate bool
+	// Apply, if set, makes Ensure use server-side apply, as FieldManager, rather than replacing the
+	// whole resource. We then own only the fields set in the Definition, and leave alone those that
+	// the server, other controllers or admins set, so EqualFunc isn't needed. The Definition must
+	// have its TypeMeta, as those loaded from YAML do.
+	Apply         bool
+	mutex         sync.Mutex
+	owner         *metav1.OwnerReference
+	latestVersion crclient.Object
+	recorder      record.EventRecorder
+	eventObj      runtime.Object
 }
 
+// FieldManager is who EnsurableImpl applies resources as, with server-side apply.
+const FieldManager = "aws-efs-operator"
+
+// Actions passed to EnsurableImpl.OnChange
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Event reasons used by EnsurableImpl
+const (
+	EventReasonCreated      = "Created"
+	EventReasonCreateFailed = "CreateFailed"
+	EventReasonUpdated      = "Updated"
+	EventReasonUpdateFailed = "UpdateFailed"
+	EventReasonDeleted      = "Deleted"
+	EventReasonDeleteFailed = "DeleteFailed"
+)
+
 // GetType implements Ensurable.
-func (e *EnsurableImpl) GetType() runtime.Object {
+func (e *EnsurableImpl) GetType() crclient.Object {
 	// To make this "safe", we return a _copy_ of e.objType. The caller is expecting to be able to
 	// use this e.g. to receive a real object from the server, and we don't want that data going into our
 	// EnsurableImpl instance. For one thing, maybe the _next_ caller is expecting it to be empty. For another,
 	// multiple threads using the same instance would be bad. Like crossing the streams.
-	return e.ObjType.DeepCopyObject()
+	return e.ObjType.DeepCopyObject().(crclient.Object)
 }
 
 // GetNamespacedName implements Ensurable.
@@ -62,27 +110,81 @@ func (e *EnsurableImpl) GetNamespacedName() types.NamespacedName {
 
 // SetOwner implements Ensurable.
 func (e *EnsurableImpl) SetOwner(owner *metav1.OwnerReference) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 	e.owner = owner
 }
 
+// SetDefinition replaces the Definition, e.g. because the configuration it was built from changed.
+// What was cached from the server is discarded, so the next Ensure compares against the new
+// Definition.
+func (e *EnsurableImpl) SetDefinition(def crclient.Object) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.Definition = def
+	e.latestVersion = nil
+}
+
+// SetEventRecorder implements Ensurable.
+func (e *EnsurableImpl) SetEventRecorder(recorder record.EventRecorder, obj runtime.Object) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.recorder = recorder
+	e.eventObj = obj
+}
+
+// event emits an Event about the resource, `obj`, if we have an EventRecorder. The message is
+// built from `verb`, the kind of the resource, and its name, plus `err` if there is one.
+func (e *EnsurableImpl) event(obj runtime.Object, eventtype, reason, verb string, err error) {
+	if e.recorder == nil {
+		return
+	}
+	target := e.eventObj
+	if target == nil {
+		target = obj
+	}
+	kind := e.kind()
+	if err != nil {
+		e.recorder.Eventf(target, eventtype, reason, "%s %s %s: %v", verb, kind, e.NamespacedName.Name, err)
+		return
+	}
+	e.recorder.Eventf(target, eventtype, reason, "%s %s %s", verb, kind, e.NamespacedName.Name)
+}
+
+// kind returns the name of the type of the resource, e.g. "PersistentVolume".
+func (e *EnsurableImpl) kind() string {
+	return reflect.TypeOf(e.ObjType).Elem().Name()
+}
+
+// changed is called after a successful `action` (one of the Action* constants) on the resource.
+func (e *EnsurableImpl) changed(action string) {
+	if e.OnChange != nil {
+		e.OnChange(action)
+	}
+}
+
+// drifted is called when Ensure has restored the resource.
+func (e *EnsurableImpl) drifted() {
+	if e.OnDrift != nil {
+		e.OnDrift()
+	}
+}
+
 // Ensure implements Ensurable.
-func (e *EnsurableImpl) Ensure(log logr.Logger, client crclient.Client) error {
+func (e *EnsurableImpl) Ensure(ctx context.Context, log logr.Logger, client crclient.Client) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.Apply {
+		return e.apply(ctx, log, client)
+	}
 	rname := e.GetNamespacedName()
 	foundObj := e.GetType()
-	if err := client.Get(context.TODO(), rname, foundObj); err != nil {
+	if err := client.Get(ctx, rname, foundObj); err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("Creating.", "resource", rname)
-			_, newObj := e.latestDefinition(nil)
-			// Clear any cached ResourceVersion, as required by Create
-			newObj.(metav1.Object).SetResourceVersion("")
-			if err = client.Create(context.TODO(), newObj); err != nil {
-				log.Error(err, "Failed to create", "resource", rname)
-				return err
-			}
-			log.Info("Created.", "resource", rname)
-			// Cache it
-			e.latestVersion = newObj
-			return nil
+			// If we have a cached version, the resource existed before, so it was deleted out from
+			// under us.
+			return e.create(ctx, log, client, e.latestVersion != nil)
 		}
 		log.Error(err, "Failed to retrieve.", "resource", rname)
 		return err
@@ -92,19 +194,40 @@ func (e *EnsurableImpl) Ensure(log logr.Logger, client crclient.Client) error {
 	equal, latestObj := e.latestDefinition(foundObj)
 	if equal {
 		log.Info("No update needed.")
+	} else if e.Recreate {
+		log.Info("Update needed. Recreating...")
+		log.V(2).Info(cmp.Diff(foundObj, latestObj))
+		if err := client.Delete(ctx, foundObj); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete.", "resource", rname)
+			e.event(foundObj, corev1.EventTypeWarning, EventReasonDeleteFailed, "Failed to delete", err)
+			return err
+		}
+		e.event(foundObj, corev1.EventTypeNormal, EventReasonDeleted, "Deleted", nil)
+		e.changed(ActionDelete)
+		// Don't recreate from what the server had
+		e.latestVersion = nil
+		return e.create(ctx, log, client, e.contentDiffers(latestObj, foundObj))
 	} else {
 		log.Info("Update needed. Updating...")
+		// Determine this before the Update overwrites latestObj with the server's response.
+		isDrift := e.contentDiffers(latestObj, foundObj)
 		// Debug: print out _how_ the objects differ.
 		// This will show what we're changing *from* as '-' and what we're changing *to* as '+'.
 		log.V(2).Info(cmp.Diff(foundObj, latestObj))
 		// Update uses ResourceVersion as a consistency marker to make sure an out-of-band update
 		// didn't happen since our Get.
-		latestObj.(metav1.Object).SetResourceVersion(foundObj.(metav1.Object).GetResourceVersion())
-		if err := client.Update(context.TODO(), latestObj); err != nil {
+		latestObj.SetResourceVersion(foundObj.GetResourceVersion())
+		if err := client.Update(ctx, latestObj); err != nil {
 			log.Error(err, "Failed to update.", "resource", rname)
+			e.event(foundObj, corev1.EventTypeWarning, EventReasonUpdateFailed, "Failed to update", err)
 			return err
 		}
 		log.Info("Updated.", "resource", rname)
+		e.event(latestObj, corev1.EventTypeNormal, EventReasonUpdated, "Updated", nil)
+		e.changed(ActionUpdate)
+		if isDrift {
+			e.drifted()
+		}
 	}
 	// Okay, we either updated successfully or didn't need an update. The cache might be good, except:
 	// - If this is the first hit for this resource, newObj is our generated skeleton definition, which
@@ -118,14 +241,126 @@ func (e *EnsurableImpl) Ensure(log logr.Logger, client crclient.Client) error {
 	return nil
 }
 
+// create creates the resource from the Definition, or the cached version if there is one. It's
+// a drift if `isDrift`.
+func (e *EnsurableImpl) create(ctx context.Context, log logr.Logger, client crclient.Client, isDrift bool) error {
+	rname := e.GetNamespacedName()
+	_, newObj := e.latestDefinition(nil)
+	// Clear any cached ResourceVersion, as required by Create
+	newObj.SetResourceVersion("")
+	if err := client.Create(ctx, newObj); err != nil {
+		log.Error(err, "Failed to create", "resource", rname)
+		e.event(newObj, corev1.EventTypeWarning, EventReasonCreateFailed, "Failed to create", err)
+		return err
+	}
+	log.Info("Created.", "resource", rname)
+	e.event(newObj, corev1.EventTypeNormal, EventReasonCreated, "Created", nil)
+	e.changed(ActionCreate)
+	if isDrift {
+		e.drifted()
+	}
+	// Cache it
+	e.latestVersion = newObj
+	return nil
+}
+
+// apply implements Ensure for an EnsurableImpl using server-side apply. The server decides whether
+// anything needs changing, so the resource is applied unless it's unchanged since we last did so.
+func (e *EnsurableImpl) apply(ctx context.Context, log logr.Logger, client crclient.Client) error {
+	rname := e.GetNamespacedName()
+	foundObj := e.GetType()
+	exists := true
+	if err := client.Get(ctx, rname, foundObj); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to retrieve.", "resource", rname)
+			return err
+		}
+		exists = false
+		log.Info("Creating.", "resource", rname)
+	} else if e.latestVersion != nil && VersionsEqual(e.latestVersion, foundObj) &&
+		(e.owner == nil || len(foundObj.GetOwnerReferences()) == 1) {
+		// (Unless we've been given an owner since.)
+		log.Info("Found. Unchanged since last applied.", "resource", rname)
+		return nil
+	} else {
+		log.Info("Found. Applying.", "resource", rname)
+	}
+
+	newObj, err := e.applyDefinition(ctx, client)
+	if err != nil && exists && e.Recreate && errors.IsInvalid(err) {
+		// Presumably an immutable field changed
+		log.Info("Update needed. Recreating...")
+		if err := client.Delete(ctx, foundObj); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete.", "resource", rname)
+			e.event(foundObj, corev1.EventTypeWarning, EventReasonDeleteFailed, "Failed to delete", err)
+			return err
+		}
+		e.event(foundObj, corev1.EventTypeNormal, EventReasonDeleted, "Deleted", nil)
+		e.changed(ActionDelete)
+		exists = false
+		newObj, err = e.applyDefinition(ctx, client)
+	}
+	if err != nil {
+		if exists {
+			log.Error(err, "Failed to update.", "resource", rname)
+			e.event(foundObj, corev1.EventTypeWarning, EventReasonUpdateFailed, "Failed to update", err)
+		} else {
+			log.Error(err, "Failed to create", "resource", rname)
+			e.event(newObj, corev1.EventTypeWarning, EventReasonCreateFailed, "Failed to create", err)
+		}
+		return err
+	}
+
+	// If we applied it before, anything we changed was changed by someone else in the meantime,
+	// unless all we did was adopt it.
+	isDrift := e.latestVersion != nil
+	if !exists {
+		log.Info("Created.", "resource", rname)
+		e.event(newObj, corev1.EventTypeNormal, EventReasonCreated, "Created", nil)
+		e.changed(ActionCreate)
+	} else if !VersionsEqual(newObj, foundObj) {
+		log.Info("Updated.", "resource", rname)
+		e.event(newObj, corev1.EventTypeNormal, EventReasonUpdated, "Updated", nil)
+		e.changed(ActionUpdate)
+		isDrift = isDrift && (e.owner == nil || len(foundObj.GetOwnerReferences()) == 1)
+	} else {
+		log.Info("No update needed.")
+		isDrift = false
+	}
+	if isDrift {
+		e.drifted()
+	}
+	// Cache the server's response, so we can tell whether the resource changed since.
+	e.latestVersion = newObj
+	return nil
+}
+
+// applyDefinition applies the Definition, with our label and owner reference, and returns the
+// resource as the server has it afterward.
+func (e *EnsurableImpl) applyDefinition(ctx context.Context, client crclient.Client) (crclient.Object, error) {
+	obj := e.Definition.DeepCopyObject().(crclient.Object)
+	MakeMeCare(obj)
+	if e.owner != nil {
+		obj.SetOwnerReferences([]metav1.OwnerReference{*e.owner})
+	}
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		// Developer error
+		return obj, fmt.Errorf("can't apply %s %s without its apiVersion and kind", e.kind(), e.NamespacedName)
+	}
+	err := client.Patch(ctx, obj, crclient.Apply, crclient.FieldOwner(FieldManager), crclient.ForceOwnership)
+	return obj, err
+}
+
 // Delete implements Ensurable
-func (e *EnsurableImpl) Delete(log logr.Logger, client crclient.Client) error {
+func (e *EnsurableImpl) Delete(ctx context.Context, log logr.Logger, client crclient.Client) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 	// Let's clear the cache in case the object needs to be recreated at some point
 	e.latestVersion = nil
 
 	rname := e.GetNamespacedName()
 	foundObj := e.GetType()
-	if err := client.Get(context.TODO(), rname, foundObj); err != nil {
+	if err := client.Get(ctx, rname, foundObj); err != nil {
 		if errors.IsNotFound(err) {
 			// Already gone. Nothing to do
 			return nil
@@ -136,16 +371,19 @@ func (e *EnsurableImpl) Delete(log logr.Logger, client crclient.Client) error {
 	}
 
 	log.Info("Deleting.", "resource", rname)
-	if err := client.Delete(context.TODO(), foundObj); err != nil {
+	if err := client.Delete(ctx, foundObj); err != nil {
 		if errors.IsNotFound(err) {
 			// It got deleted out-of-band. That's fine
 			return nil
 		}
 		log.Error(err, "Failed to delete.", "resource", rname)
+		e.event(foundObj, corev1.EventTypeWarning, EventReasonDeleteFailed, "Failed to delete", err)
 		return err
 	}
 
 	// Cool.
+	e.event(foundObj, corev1.EventTypeNormal, EventReasonDeleted, "Deleted", nil)
+	e.changed(ActionDelete)
 	return nil
 }
 
@@ -155,19 +393,13 @@ func AlwaysEqual(local, server runtime.Object) bool {
 	return true
 }
 
-// EqualOtherThanMeta is a DeepEquals that ignores ObjectMeta and TypeMeta.
-// Use when a DeepEqual on Spec won't work, e.g. when the meat of the object is at the top level
-// and/or there _is_ no Spec.
-func EqualOtherThanMeta(local, server runtime.Object) bool {
-	return cmp.Equal(local, server, cmpopts.IgnoreTypes(metav1.ObjectMeta{}, metav1.TypeMeta{}))
-}
-
-func (e *EnsurableImpl) latestDefinition(serverObj runtime.Object) (bool, runtime.Object) {
+func (e *EnsurableImpl) latestDefinition(serverObj crclient.Object) (bool, crclient.Object) {
 	// If we cached one, use it, because it's not only right, it's complete
 	def := e.latestVersion
 	if def == nil {
-		// Let this panic if none defined (developer error)
-		def = e.Definition
+		// Let this panic if none defined (developer error). Copy it, so the caller can tell whether
+		// a new Definition differs from this one.
+		def = e.Definition.DeepCopyObject().(crclient.Object)
 		// Make sure this object triggers our watcher
 		MakeMeCare(def)
 	}
@@ -177,7 +409,7 @@ func (e *EnsurableImpl) latestDefinition(serverObj runtime.Object) (bool, runtim
 	// the fact that we have a chicken/egg problem with statics being created on startup, before
 	// we can count on the CRD existing.
 	if e.owner != nil {
-		def.(metav1.Object).SetOwnerReferences([]metav1.OwnerReference{*e.owner})
+		def.SetOwnerReferences([]metav1.OwnerReference{*e.owner})
 	}
 	if serverObj != nil && e.equal(def, serverObj) {
 		// If they're "equal", return the foundObj, because there are cases where it's newer or more complete
@@ -214,6 +446,15 @@ func (e *EnsurableImpl) equal(local, server runtime.Object) bool {
 	return e.EqualFunc(local, server)
 }
 
+// contentDiffers is like the inverse of `equal`, except that it ignores the owner reference. It
+// distinguishes an update restoring a resource that had drifted from one that merely adopts it.
+func (e *EnsurableImpl) contentDiffers(local, server runtime.Object) bool {
+	if VersionsEqual(local, server) {
+		return false
+	}
+	return !DoICare(server) || !e.EqualFunc(local, server)
+}
+
 // VersionsEqual compares the generation of two objects. This can be used first in an `equal`
 // because if the generation hasn't changed, there's no need to check further.
 func VersionsEqual(local, server runtime.Object) bool {
@@ -2,6 +2,8 @@ package util
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"testing"
 
 	fx "openshift/aws-efs-operator/pkg/fixtures"
@@ -13,6 +15,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var todo context.Context = context.TODO()
@@ -22,8 +26,12 @@ type mocks struct {
 	ensurable           *EnsurableImpl
 	log                 *fx.MockLogger
 	client              *fx.MockClient
-	getTypeAndServerObj runtime.Object
-	getterAndCachedObj  runtime.Object
+	recorder            *record.FakeRecorder
+	getTypeAndServerObj crclient.Object
+	getterAndCachedObj  crclient.Object
+	// Counts of calls to OnChange, per action, and to OnDrift during the test
+	actions map[string]int
+	drifts  *int
 }
 
 func mkMocks(ctrl *gomock.Controller) mocks {
@@ -37,12 +45,58 @@ func mkMocks(ctrl *gomock.Controller) mocks {
 		// By not setting one of them, we're asserting it won't be called, since
 		// doing so would attempt to dereference a nil function pointer.
 	}
+	recorder := record.NewFakeRecorder(10)
+	ensurable.SetEventRecorder(recorder, nil)
+	drifts := 0
+	ensurable.OnDrift = func() { drifts++ }
+	actions := make(map[string]int)
+	ensurable.OnChange = func(action string) { actions[action]++ }
 	return mocks{
 		ensurable:           &ensurable,
 		log:                 fx.NewMockLogger(ctrl),
 		client:              fx.NewMockClient(ctrl),
+		recorder:            recorder,
 		getTypeAndServerObj: o1,
 		getterAndCachedObj:  o2,
+		actions:             actions,
+		drifts:              &drifts,
+	}
+}
+
+// createdDefinition returns what the `m`ocked ensurable should create from its Definition: a copy,
+// labeled so our watcher notices it.
+func createdDefinition(m mocks) crclient.Object {
+	obj := m.ensurable.Definition.DeepCopyObject().(crclient.Object)
+	MakeMeCare(obj)
+	return obj
+}
+
+// checkHooks fails the `t`est if OnChange wasn't called the `expected` number of times per
+// action, or OnDrift `expectDrifts` times.
+func checkHooks(t *testing.T, m mocks, expected map[string]int, expectDrifts int) {
+	t.Helper()
+	for _, action := range []string{ActionCreate, ActionUpdate, ActionDelete} {
+		if m.actions[action] != expected[action] {
+			t.Fatalf("Expected %d %s actions but got %d", expected[action], action, m.actions[action])
+		}
+	}
+	if *m.drifts != expectDrifts {
+		t.Fatalf("Expected %d drifts but got %d", expectDrifts, *m.drifts)
+	}
+}
+
+// checkEvents fails the `t`est if the Events recorded via `m` aren't `expected`.
+func checkEvents(t *testing.T, m mocks, expected ...string) {
+	t.Helper()
+	events := []string{}
+	for len(m.recorder.Events) > 0 {
+		events = append(events, <-m.recorder.Events)
+	}
+	if len(events) == 0 && len(expected) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Expected Events %q but got %q", expected, events)
 	}
 }
 
@@ -58,14 +112,16 @@ func TestEnsureNotFoundCreateError(t *testing.T) {
 		// Get called with the ObjType
 		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound),
 		m.log.EXPECT().Info("Creating.", "resource", nsname),
-		// Create called with the return from DefinitionGetter
-		m.client.EXPECT().Create(todo, m.getterAndCachedObj).Return(fx.AlreadyExists),
+		// Create called with (a copy of) the Definition
+		m.client.EXPECT().Create(todo, createdDefinition(m)).Return(fx.AlreadyExists),
 		m.log.EXPECT().Error(fx.AlreadyExists, "Failed to create", "resource", nsname),
 	)
 
-	if err := m.ensurable.Ensure(m.log, m.client); err != fx.AlreadyExists {
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != fx.AlreadyExists {
 		t.Errorf("Ensure(): expected error AlreadyExists, got %v", err)
 	}
+	checkEvents(t, m, "Warning CreateFailed Failed to create Pod : AlreadyExists")
+	checkHooks(t, m, nil, 0)
 }
 
 // TestEnsureNotFoundCreateSuccess tests the bootstrap green path where we successfully create the resource.
@@ -75,6 +131,32 @@ func TestEnsureNotFoundCreateSuccess(t *testing.T) {
 	m := mkMocks(ctrl)
 	m.ensurable.Definition = m.getterAndCachedObj
 
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound),
+		m.log.EXPECT().Info("Creating.", "resource", nsname),
+		m.client.EXPECT().Create(todo, createdDefinition(m)).Return(nil),
+		m.log.EXPECT().Info("Created.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Created Created Pod ")
+	checkHooks(t, m, map[string]int{ActionCreate: 1}, 0)
+	// The Definition itself is left alone, so a replacement can be compared with it
+	if DoICare(m.ensurable.Definition) {
+		t.Fatal("Definition was modified.")
+	}
+}
+
+// TestEnsureRecreate tests the path where a resource we already ensured was deleted out of band,
+// so we recreate it from the cache, which counts as drift.
+func TestEnsureRecreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkMocks(ctrl)
+	m.ensurable.latestVersion = m.getterAndCachedObj
+
 	gomock.InOrder(
 		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound),
 		m.log.EXPECT().Info("Creating.", "resource", nsname),
@@ -82,9 +164,11 @@ func TestEnsureNotFoundCreateSuccess(t *testing.T) {
 		m.log.EXPECT().Info("Created.", "resource", nsname),
 	)
 
-	if err := m.ensurable.Ensure(m.log, m.client); err != nil {
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
 		t.Errorf("Ensure(): expected nil, got %v", err)
 	}
+	checkEvents(t, m, "Normal Created Created Pod ")
+	checkHooks(t, m, map[string]int{ActionCreate: 1}, 1)
 }
 
 // TestEnsureGetError tests when the initial GET fails with an unhandled (non-404) error.
@@ -98,7 +182,7 @@ func TestEnsureGetError(t *testing.T) {
 		m.log.EXPECT().Error(fx.AlreadyExists, "Failed to retrieve.", "resource", nsname),
 	)
 
-	if err := m.ensurable.Ensure(m.log, m.client); err != fx.AlreadyExists {
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != fx.AlreadyExists {
 		t.Errorf("Ensure(): expected error AlreadyExists, got %v", err)
 	}
 }
@@ -124,10 +208,12 @@ func TestEnsureExistsNoUpdate(t *testing.T) {
 		m.log.EXPECT().Info("No update needed."),
 	)
 
-	if err := m.ensurable.Ensure(m.log, m.client); err != nil {
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
 		t.Errorf("Ensure(): expected nil, got %v", err)
 	}
+	checkEvents(t, m)
 	// The latestVersion got overwritten, but with the same value
+	checkHooks(t, m, nil, 0)
 	if diff := cmp.Diff(m.ensurable.latestVersion, m.getTypeAndServerObj); diff != "" {
 		t.Fatalf("Bogus latestVersion:\n%s", diff)
 	}
@@ -156,9 +242,11 @@ func TestEnsureExistsUpdateError(t *testing.T) {
 		m.log.EXPECT().Error(fx.NotFound, "Failed to update.", "resource", nsname),
 	)
 
-	if err := m.ensurable.Ensure(m.log, m.client); err != fx.NotFound {
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != fx.NotFound {
 		t.Errorf("Ensure(): expected error NotFound, got %v", err)
 	}
+	checkEvents(t, m, "Warning UpdateFailed Failed to update Pod : NotFound")
+	checkHooks(t, m, nil, 0)
 	// The latestVersion didn't get reset
 	if m.ensurable.latestVersion != m.getterAndCachedObj {
 		t.Fatalf("Bogus latestVersion.\nExpected: %v\nGot:     %v", m.getterAndCachedObj, m.ensurable.latestVersion)
@@ -194,9 +282,11 @@ func TestEnsureExistsUpdateSuccess(t *testing.T) {
 		m.log.EXPECT().Info("Updated.", "resource", nsname),
 	)
 
-	if err := m.ensurable.Ensure(m.log, m.client); err != nil {
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
 		t.Errorf("Ensure(): expected nil, got %v", err)
 	}
+	checkEvents(t, m, "Normal Updated Updated Pod ")
+	checkHooks(t, m, map[string]int{ActionUpdate: 1}, 1)
 	// The latestVersion got overwritten, but with the same value
 	if m.ensurable.latestVersion != m.getterAndCachedObj {
 		t.Fatalf("Bogus latestVersion.\nExpected: %v\nGot:     %v", m.getterAndCachedObj, m.ensurable.latestVersion)
@@ -207,6 +297,286 @@ func TestEnsureExistsUpdateSuccess(t *testing.T) {
 	}
 }
 
+// TestEnsureExistsRecreate tests the path where the resource exists and needs an update, but
+// can't be updated, so it's deleted and created anew.
+func TestEnsureExistsRecreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkMocks(ctrl)
+	m.ensurable.Definition = m.getterAndCachedObj
+	m.ensurable.Recreate = true
+	// The server object isn't labeled, so it needs an update, and that counts as drift. By not
+	// defining EqualFunc, we prove that it doesn't get called.
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Checking whether update is needed.", "resource", nsname),
+		m.log.EXPECT().Info("Update needed. Recreating..."),
+		m.log.EXPECT().V(2).Return(m.log),
+		// Don't bother to check the debug message
+		m.log.EXPECT().Info(gomock.Any()),
+		m.client.EXPECT().Delete(todo, m.getTypeAndServerObj).Return(nil),
+		m.client.EXPECT().Create(todo, createdDefinition(m)).Return(nil),
+		m.log.EXPECT().Info("Created.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Deleted Deleted Pod ", "Normal Created Created Pod ")
+	checkHooks(t, m, map[string]int{ActionDelete: 1, ActionCreate: 1}, 1)
+	if expected := createdDefinition(m); !reflect.DeepEqual(m.ensurable.latestVersion, expected) {
+		t.Fatalf("Bogus latestVersion.\nExpected: %v\nGot:     %v", expected, m.ensurable.latestVersion)
+	}
+}
+
+// mkApplyMocks returns mocks for an EnsurableImpl that uses server-side apply.
+func mkApplyMocks(ctrl *gomock.Controller) mocks {
+	m := mkMocks(ctrl)
+	m.ensurable.Apply = true
+	m.ensurable.Definition = &corev1.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}}
+	return m
+}
+
+// expectApply expects the Definition to be applied, and makes the server respond with the
+// `resourceVersion`, or the error `err`.
+func expectApply(m mocks, resourceVersion string, err error) *gomock.Call {
+	return m.client.EXPECT().Patch(todo, gomock.Any(), crclient.Apply, crclient.FieldOwner(FieldManager), crclient.ForceOwnership).
+		DoAndReturn(func(_ context.Context, obj crclient.Object, _ crclient.Patch, _ ...crclient.PatchOption) error {
+			if !DoICare(obj) {
+				return fmt.Errorf("applied object isn't labeled: %v", obj)
+			}
+			if err == nil {
+				obj.(metav1.Object).SetResourceVersion(resourceVersion)
+			}
+			return err
+		})
+}
+
+// TestEnsureApplyCreate tests the path where we apply a resource that doesn't exist yet.
+func TestEnsureApplyCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound),
+		m.log.EXPECT().Info("Creating.", "resource", nsname),
+		expectApply(m, "1", nil),
+		m.log.EXPECT().Info("Created.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Created Created Pod ")
+	checkHooks(t, m, map[string]int{ActionCreate: 1}, 0)
+	if rv := m.ensurable.latestVersion.(metav1.Object).GetResourceVersion(); rv != "1" {
+		t.Fatalf("Expected the server's response to be cached, but got ResourceVersion %q", rv)
+	}
+	// The Definition itself is left alone
+	if DoICare(m.ensurable.Definition) {
+		t.Fatal("Definition was modified.")
+	}
+}
+
+// TestEnsureApplyCreateError tests the path where applying a new resource fails.
+func TestEnsureApplyCreateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound),
+		m.log.EXPECT().Info("Creating.", "resource", nsname),
+		expectApply(m, "", fx.AlreadyExists),
+		m.log.EXPECT().Error(fx.AlreadyExists, "Failed to create", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != fx.AlreadyExists {
+		t.Errorf("Ensure(): expected error AlreadyExists, got %v", err)
+	}
+	checkEvents(t, m, "Warning CreateFailed Failed to create Pod : AlreadyExists")
+	checkHooks(t, m, nil, 0)
+	if m.ensurable.latestVersion != nil {
+		t.Fatalf("Expected nothing to be cached, but got %v", m.ensurable.latestVersion)
+	}
+}
+
+// TestEnsureApplyUpdate tests applying a resource that exists, first with changes, then without
+// any (e.g. because only the server defaults or others' fields differ from the Definition), and
+// finally when it's unchanged since.
+func TestEnsureApplyUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+	// Fields we don't own don't matter. By not defining EqualFunc, we prove it doesn't get called.
+	m.getTypeAndServerObj.(*corev1.Pod).SetResourceVersion("1")
+	m.getTypeAndServerObj.(*corev1.Pod).Labels = map[string]string{"admin": "label"}
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Applying.", "resource", nsname),
+		expectApply(m, "2", nil),
+		m.log.EXPECT().Info("Updated.", "resource", nsname),
+	)
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Updated Updated Pod ")
+	// The first time around, it's not drift: it may just be what a previous incarnation left.
+	checkHooks(t, m, map[string]int{ActionUpdate: 1}, 0)
+
+	m.getTypeAndServerObj.(*corev1.Pod).SetResourceVersion("3")
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Applying.", "resource", nsname),
+		expectApply(m, "3", nil),
+		m.log.EXPECT().Info("No update needed."),
+	)
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m)
+	checkHooks(t, m, map[string]int{ActionUpdate: 1}, 0)
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Unchanged since last applied.", "resource", nsname),
+	)
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m)
+	checkHooks(t, m, map[string]int{ActionUpdate: 1}, 0)
+}
+
+// TestEnsureApplyDrift tests applying a resource that changed since we last applied it, and that
+// the server changed back.
+func TestEnsureApplyDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+	m.ensurable.latestVersion = m.getterAndCachedObj
+	m.getterAndCachedObj.(metav1.Object).SetResourceVersion("1")
+	m.getTypeAndServerObj.(metav1.Object).SetResourceVersion("2")
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Applying.", "resource", nsname),
+		expectApply(m, "3", nil),
+		m.log.EXPECT().Info("Updated.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Updated Updated Pod ")
+	checkHooks(t, m, map[string]int{ActionUpdate: 1}, 1)
+}
+
+// TestEnsureApplyUpdateError tests the path where applying an existing resource fails.
+func TestEnsureApplyUpdateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+	m.ensurable.latestVersion = m.getterAndCachedObj
+	m.getTypeAndServerObj.(metav1.Object).SetResourceVersion("2")
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Applying.", "resource", nsname),
+		// Without Recreate, this isn't special
+		expectApply(m, "", fx.Invalid),
+		m.log.EXPECT().Error(fx.Invalid, "Failed to update.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != fx.Invalid {
+		t.Errorf("Ensure(): expected error Invalid, got %v", err)
+	}
+	checkEvents(t, m, "Warning UpdateFailed Failed to update Pod : Invalid")
+	checkHooks(t, m, nil, 0)
+	if m.ensurable.latestVersion != m.getterAndCachedObj {
+		t.Fatalf("Bogus latestVersion.\nExpected: %v\nGot:     %v", m.getterAndCachedObj, m.ensurable.latestVersion)
+	}
+}
+
+// TestEnsureApplyRecreate tests the path where applying an existing resource is rejected, e.g.
+// because an immutable field changed, so it's deleted and applied anew.
+func TestEnsureApplyRecreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+	m.ensurable.Recreate = true
+	m.getTypeAndServerObj.(metav1.Object).SetResourceVersion("1")
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Applying.", "resource", nsname),
+		expectApply(m, "", fx.Invalid),
+		m.log.EXPECT().Info("Update needed. Recreating..."),
+		m.client.EXPECT().Delete(todo, m.getTypeAndServerObj).Return(nil),
+		expectApply(m, "2", nil),
+		m.log.EXPECT().Info("Created.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Deleted Deleted Pod ", "Normal Created Created Pod ")
+	checkHooks(t, m, map[string]int{ActionDelete: 1, ActionCreate: 1}, 0)
+}
+
+// TestEnsureApplyNoTypeMeta tests that a Definition without its apiVersion and kind isn't applied.
+func TestEnsureApplyNoTypeMeta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkApplyMocks(ctrl)
+	m.ensurable.Definition = m.getterAndCachedObj
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound),
+		m.log.EXPECT().Info("Creating.", "resource", nsname),
+		m.log.EXPECT().Error(gomock.Any(), "Failed to create", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err == nil {
+		t.Error("Ensure(): expected an error, got nil")
+	}
+	checkHooks(t, m, nil, 0)
+}
+
+// TestEnsureAdopt tests the path where the resource is as defined, but needs an update to set its
+// owner reference. That's not drift.
+func TestEnsureAdopt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mkMocks(ctrl)
+	m.ensurable.latestVersion = m.getterAndCachedObj
+	m.ensurable.SetOwner(&metav1.OwnerReference{Name: "owner"})
+	// Same versions, so the content is the same. By not defining EqualFunc, we prove that it
+	// doesn't get called.
+	m.getterAndCachedObj.(metav1.Object).SetResourceVersion("abc")
+	m.getTypeAndServerObj.(metav1.Object).SetResourceVersion("abc")
+
+	gomock.InOrder(
+		m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(nil),
+		m.log.EXPECT().Info("Found. Checking whether update is needed.", "resource", nsname),
+		m.log.EXPECT().Info("Update needed. Updating..."),
+		m.log.EXPECT().V(2).Return(m.log),
+		// Don't bother to check the debug message
+		m.log.EXPECT().Info(gomock.Any()),
+		m.client.EXPECT().Update(todo, m.getterAndCachedObj).Return(nil),
+		m.log.EXPECT().Info("Updated.", "resource", nsname),
+	)
+
+	if err := m.ensurable.Ensure(todo, m.log, m.client); err != nil {
+		t.Errorf("Ensure(): expected nil, got %v", err)
+	}
+	checkEvents(t, m, "Normal Updated Updated Pod ")
+	checkHooks(t, m, map[string]int{ActionUpdate: 1}, 0)
+}
+
 // TestDeleteAlreadyGone tests the green path where the resource was already deleted
 func TestDeleteAlreadyGone(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -215,7 +585,7 @@ func TestDeleteAlreadyGone(t *testing.T) {
 
 	m.client.EXPECT().Get(todo, nsname, m.getTypeAndServerObj).Return(fx.NotFound)
 
-	if err := m.ensurable.Delete(m.log, m.client); err != nil {
+	if err := m.ensurable.Delete(todo, m.log, m.client); err != nil {
 		t.Errorf("Delete(): expected nil, got %v", err)
 	}
 }
@@ -231,7 +601,7 @@ func TestDeleteGetError(t *testing.T) {
 		m.log.EXPECT().Error(fx.AlreadyExists, "Failed to retrieve.", "resource", nsname),
 	)
 
-	if err := m.ensurable.Delete(m.log, m.client); err != fx.AlreadyExists {
+	if err := m.ensurable.Delete(todo, m.log, m.client); err != fx.AlreadyExists {
 		t.Errorf("Delete(): expected error %v; got %v", fx.AlreadyExists, err)
 	}
 }
@@ -249,9 +619,10 @@ func TestDeleteOutOfBand(t *testing.T) {
 		m.client.EXPECT().Delete(todo, m.getTypeAndServerObj).Return(fx.NotFound),
 	)
 
-	if err := m.ensurable.Delete(m.log, m.client); err != nil {
+	if err := m.ensurable.Delete(todo, m.log, m.client); err != nil {
 		t.Errorf("Delete(): expected nil, got %v", err)
 	}
+	checkEvents(t, m)
 }
 
 // TestDeleteDeleteError tests the error path where our Delete call fails.
@@ -267,9 +638,11 @@ func TestDeleteDeleteError(t *testing.T) {
 		m.log.EXPECT().Error(fx.AlreadyExists, "Failed to delete.", "resource", nsname),
 	)
 
-	if err := m.ensurable.Delete(m.log, m.client); err != fx.AlreadyExists {
+	if err := m.ensurable.Delete(todo, m.log, m.client); err != fx.AlreadyExists {
 		t.Errorf("Delete(): expected error %v; got %v", fx.AlreadyExists, err)
 	}
+	checkEvents(t, m, "Warning DeleteFailed Failed to delete Pod : AlreadyExists")
+	checkHooks(t, m, nil, 0)
 }
 
 // TestDeleteDeletes tests the green path where the resource is found and deleted successfully
@@ -284,9 +657,11 @@ func TestDeleteDeletes(t *testing.T) {
 		m.client.EXPECT().Delete(todo, m.getTypeAndServerObj).Return(nil),
 	)
 
-	if err := m.ensurable.Delete(m.log, m.client); err != nil {
+	if err := m.ensurable.Delete(todo, m.log, m.client); err != nil {
 		t.Errorf("Delete(): expected nil, got %v", err)
 	}
+	checkEvents(t, m, "Normal Deleted Deleted Pod ")
+	checkHooks(t, m, map[string]int{ActionDelete: 1}, 0)
 }
 
 // TestGetType proves that GetType() returns a new object rather than reusing the one the
@@ -12,6 +12,7 @@ Keeping this abstracted lets us change the logic easily in the future if we need
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -32,13 +33,13 @@ var log = logf.Log.WithName("controller_utils")
 // (cluster-level resources that are "owned" by the controller) or because the owning and
 // owned objects are in different namespaces.
 var ICarePredicate = predicate.Funcs{
-	CreateFunc: func(e event.CreateEvent) bool { return passes(e.Object, e.Meta) },
-	DeleteFunc: func(e event.DeleteEvent) bool { return passes(e.Object, e.Meta) },
+	CreateFunc: func(e event.CreateEvent) bool { return passes(e.Object) },
+	DeleteFunc: func(e event.DeleteEvent) bool { return passes(e.Object) },
 	// UpdateFunc passes if *either* the new or old object is one we care about.
 	UpdateFunc: func(e event.UpdateEvent) bool {
-		return passes(e.ObjectOld, e.MetaOld) || passes(e.ObjectNew, e.MetaNew)
+		return passes(e.ObjectOld) || passes(e.ObjectNew)
 	},
-	GenericFunc: func(e event.GenericEvent) bool { return passes(e.Object, e.Meta) },
+	GenericFunc: func(e event.GenericEvent) bool { return passes(e.Object) },
 }
 
 // DoICare answers whether our object will trigger our watcher.
@@ -61,14 +62,16 @@ func MakeMeCare(obj runtime.Object) {
 	metaObj.GetLabels()[labelKey] = labelValue
 }
 
-func passes(obj runtime.Object, meta metav1.Object) bool {
+// ICareSelector is a List option selecting the objects we care about, i.e. those MakeMeCare was
+// applied to.
+func ICareSelector() crclient.ListOption {
+	return crclient.MatchingLabels{labelKey: labelValue}
+}
+
+func passes(obj crclient.Object) bool {
 	if obj == nil {
 		log.Error(nil, "No object for event!")
 		return false
 	}
-	if meta == nil {
-		log.Error(nil, "No metadata for event object!")
-		return false
-	}
 	return DoICare(obj)
 }
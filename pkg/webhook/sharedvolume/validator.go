@@ -0,0 +1,159 @@
+package sharedvolume
+
+/**
+Validating admission webhook for SharedVolume resources. Its job is to reject an inconsistent Spec
+at creation time, and to make the SharedVolume Spec truly immutable by rejecting edits at admission
+time, rather than letting them land and having the sharedvolume controller revert them after the
+fact (see `uneditSharedVolume`).
+*/
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"openshift/aws-efs-operator/config"
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+	"openshift/aws-efs-operator/pkg/policy"
+
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ValidatePath is the URL path at which the SharedVolume validating webhook is served. It must
+// match the `webhookPath` registered in the ClusterServiceVersion's `webhookdefinitions`.
+const ValidatePath = "/validate-sharedvolume"
+
+var log = logf.Log.WithName("webhook_sharedvolume")
+
+// Add registers the SharedVolume validating webhook with the Manager's webhook server.
+func Add(mgr manager.Manager) error {
+	// Use the manager's (cached) client to look up SharedVolumePolicies. The sharedvolume
+	// controller watches them anyway.
+	v := &Validator{reader: mgr.GetClient()}
+	// The operator itself is allowed to change the Spec, so that `uneditSharedVolume` can still
+	// revert an edit that slipped in while the webhook was unavailable.
+	if ns, err := k8sutil.GetOperatorNamespace(); err == nil {
+		v.operatorUsername = fmt.Sprintf("system:serviceaccount:%s:%s", ns, config.OperatorName)
+	} else {
+		log.Info("Couldn't discover operator namespace; not exempting the operator from validation.", "error", err.Error())
+	}
+	mgr.GetWebhookServer().Register(ValidatePath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// blank assignments to verify that Validator implements the interfaces we need
+var _ admission.Handler = &Validator{}
+var _ admission.DecoderInjector = &Validator{}
+
+// Validator is an admission.Handler rejecting invalid SharedVolumes and changes to their Spec.
+type Validator struct {
+	// The decoder is injected by the webhook server when the handler is registered.
+	decoder *admission.Decoder
+	// Requests from this user (the operator's ServiceAccount) are always allowed.
+	operatorUsername string
+	// For looking up SharedVolumePolicies. If nil, policies aren't enforced at admission.
+	reader client.Reader
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (v *Validator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	// We only register for CREATEs and UPDATEs, but be defensive in case the webhook configuration
+	// is broader.
+	switch req.Operation {
+	case admissionv1.Create:
+		return v.handleCreate(ctx, req)
+	case admissionv1.Update:
+		return v.handleUpdate(req)
+	}
+	return admission.Allowed("")
+}
+
+// handleCreate rejects a new SharedVolume whose Spec is inconsistent.
+func (v *Validator) handleCreate(ctx context.Context, req admission.Request) admission.Response {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+
+	sv := &awsefsv1alpha1.SharedVolume{}
+	if err := v.decoder.DecodeRaw(req.Object, sv); err != nil {
+		reqLogger.Error(err, "Couldn't decode SharedVolume")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if errs := sv.Spec.Validate(field.NewPath("spec")); len(errs) != 0 {
+		reqLogger.Info("Rejecting invalid SharedVolume spec", "errors", errs.ToAggregate().Error())
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	// A SharedVolume using a SharedVolumeSource doesn't name the IDs, so the controller checks it
+	// against the policies once it has resolved the source.
+	if v.reader != nil && sv.Spec.Source == "" {
+		message, err := policy.Check(ctx, v.reader, req.Namespace, sv.Spec.FileSystemID, sv.Spec.AccessPointID)
+		if err != nil {
+			reqLogger.Error(err, "Failed to check SharedVolumePolicies")
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if message != "" {
+			reqLogger.Info("Rejecting SharedVolume violating policy", "message", message)
+			return admission.Denied(message)
+		}
+	}
+	return admission.Allowed("")
+}
+
+// handleUpdate rejects changes to the Spec of an existing SharedVolume.
+func (v *Validator) handleUpdate(req admission.Request) admission.Response {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+
+	if v.operatorUsername != "" && req.UserInfo.Username == v.operatorUsername {
+		return admission.Allowed("")
+	}
+
+	newSV := &awsefsv1alpha1.SharedVolume{}
+	if err := v.decoder.DecodeRaw(req.Object, newSV); err != nil {
+		reqLogger.Error(err, "Couldn't decode SharedVolume")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	oldSV := &awsefsv1alpha1.SharedVolume{}
+	if err := v.decoder.DecodeRaw(req.OldObject, oldSV); err != nil {
+		reqLogger.Error(err, "Couldn't decode original SharedVolume")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if errs := validateUpdate(oldSV, newSV); len(errs) != 0 {
+		reqLogger.Info("Rejecting change to SharedVolume spec", "errors", errs.ToAggregate().Error())
+		return admission.Denied(fmt.Sprintf(
+			"%s. The spec of a SharedVolume can't be changed once it is created. "+
+				"If you need to attach to a different file system or access point, "+
+				"delete the SharedVolume and create a new one.",
+			errs.ToAggregate().Error()))
+	}
+	return admission.Allowed("")
+}
+
+// validateUpdate returns a list of errors describing the fields that differ between the Specs of
+// `oldSV` and `newSV`. Metadata and Status are allowed to change.
+func validateUpdate(oldSV, newSV *awsefsv1alpha1.SharedVolume) field.ErrorList {
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+	// Call out the individual fields where we can, so the message is readable...
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(
+		newSV.Spec.FileSystemID, oldSV.Spec.FileSystemID, specPath.Child("fileSystemID"))...)
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(
+		newSV.Spec.AccessPointID, oldSV.Spec.AccessPointID, specPath.Child("accessPointID"))...)
+	// ...but make sure nothing else in the Spec slips through.
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, apivalidation.ValidateImmutableField(newSV.Spec, oldSV.Spec, specPath)...)
+	}
+	return allErrs
+}
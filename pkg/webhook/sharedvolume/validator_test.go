@@ -0,0 +1,258 @@
+package sharedvolume
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	awsefsv1alpha1 "openshift/aws-efs-operator/pkg/apis/awsefs/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	// TODO: pkg/client/fake is deprecated, replace with pkg/envtest
+	// nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// newValidator returns a Validator with a decoder that knows about SharedVolumes, as the webhook
+// server would inject.
+func newValidator(t *testing.T) *Validator {
+	sch := runtime.NewScheme()
+	if err := awsefsv1alpha1.SchemeBuilder.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Validator{}
+	if err := v.InjectDecoder(decoder); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func mkSV(fsid, apid string) *awsefsv1alpha1.SharedVolume {
+	return &awsefsv1alpha1.SharedVolume{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: awsefsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "SharedVolume",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sv",
+			Namespace: "proj1",
+		},
+		Spec: awsefsv1alpha1.SharedVolumeSpec{
+			FileSystemID:  fsid,
+			AccessPointID: apid,
+		},
+	}
+}
+
+func mkRequest(t *testing.T, op admissionv1.Operation, oldSV, newSV *awsefsv1alpha1.SharedVolume) admission.Request {
+	raw := func(sv *awsefsv1alpha1.SharedVolume) runtime.RawExtension {
+		if sv == nil {
+			return runtime.RawExtension{}
+		}
+		b, err := json.Marshal(sv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: op,
+			Namespace: "proj1",
+			Name:      "sv",
+			Object:    raw(newSV),
+			OldObject: raw(oldSV),
+		},
+	}
+}
+
+func TestHandle(t *testing.T) {
+	const (
+		fs1 = "fs-000001"
+		fs2 = "fs-000002"
+		ap1 = "fsap-1111111d"
+		ap2 = "fsap-2222222e"
+	)
+	relabeled := mkSV(fs1, ap1)
+	relabeled.SetLabels(map[string]string{"foo": "bar"})
+	statused := mkSV(fs1, ap1)
+	statused.Status.Phase = awsefsv1alpha1.SharedVolumeReady
+	provisioned := mkSV(fs1, "")
+	provisioned.Spec.AccessPoint = &awsefsv1alpha1.AccessPointSpec{
+		PosixUser: awsefsv1alpha1.PosixUser{UID: 1000, GID: 1000},
+	}
+	both := mkSV(fs1, ap1)
+	both.Spec.AccessPoint = provisioned.Spec.AccessPoint
+	claimed := mkSV(fs1, ap1)
+	claimed.Spec.ClaimName = "data"
+	claimed.Spec.ClaimLabels = map[string]string{"backup": "daily"}
+	claimed.Spec.ClaimAnnotations = map[string]string{"example.com/owner": "team-a"}
+	badClaimName := mkSV(fs1, ap1)
+	badClaimName.Spec.ClaimName = "Not_A_Name"
+	reservedLabel := mkSV(fs1, ap1)
+	reservedLabel.Spec.ClaimLabels = map[string]string{"openshift.io/aws-efs-operator-owned": "false"}
+	badLabel := mkSV(fs1, ap1)
+	badLabel.Spec.ClaimLabels = map[string]string{"backup": "not a value"}
+	encrypted := mkSV(fs1, ap1)
+	encrypted.Spec.EncryptInTransit = true
+	encrypted.Spec.IAMAuthorization = true
+	iamOnly := mkSV(fs1, ap1)
+	iamOnly.Spec.IAMAuthorization = true
+	renamed := mkSV(fs1, ap1)
+	renamed.Spec.ClaimName = "other"
+	classed := mkSV(fs1, ap1)
+	classed.Spec.StorageClassName = "efs-scratch"
+	badClass := mkSV(fs1, ap1)
+	badClass.Spec.StorageClassName = "Not_A_Name"
+	sourced := mkSV("", "")
+	sourced.Spec.Source = "shared"
+	sourcedWithIDs := mkSV(fs1, ap1)
+	sourcedWithIDs.Spec.Source = "shared"
+	resourced := mkSV("", "")
+	resourced.Spec.Source = "other"
+
+	tests := []struct {
+		name       string
+		op         admissionv1.Operation
+		oldSV      *awsefsv1alpha1.SharedVolume
+		newSV      *awsefsv1alpha1.SharedVolume
+		allowed    bool
+		wantReason []string
+	}{
+		{"create", admissionv1.Create, nil, mkSV(fs1, ap1), true, nil},
+		{"create provisioned", admissionv1.Create, nil, provisioned, true, nil},
+		{"create without access point", admissionv1.Create, nil, mkSV(fs1, ""), false,
+			[]string{"spec.accessPointID", "Required"}},
+		{"create with both access points", admissionv1.Create, nil, both, false,
+			[]string{"spec.accessPoint", "Forbidden"}},
+		{"create with claim name, labels and annotations", admissionv1.Create, nil, claimed, true, nil},
+		{"create with bad claim name", admissionv1.Create, nil, badClaimName, false,
+			[]string{"spec.claimName", "Invalid value"}},
+		{"create with reserved label", admissionv1.Create, nil, reservedLabel, false,
+			[]string{"spec.claimLabels[openshift.io/aws-efs-operator-owned]", "reserved"}},
+		{"create with bad label", admissionv1.Create, nil, badLabel, false,
+			[]string{"spec.claimLabels", "Invalid value"}},
+		{"create with tls and iam", admissionv1.Create, nil, encrypted, true, nil},
+		{"create with storage class", admissionv1.Create, nil, classed, true, nil},
+		{"create with bad storage class", admissionv1.Create, nil, badClass, false,
+			[]string{"spec.storageClassName", "Invalid value"}},
+		{"change storage class", admissionv1.Update, mkSV(fs1, ap1), classed, false,
+			[]string{"spec", "immutable"}},
+		{"create from source", admissionv1.Create, nil, sourced, true, nil},
+		{"create from source with IDs", admissionv1.Create, nil, sourcedWithIDs, false,
+			[]string{"spec.fileSystemID", "spec.accessPointID", "may not be specified together with source"}},
+		{"create without file system", admissionv1.Create, nil, mkSV("", ap1), false,
+			[]string{"spec.fileSystemID", "Required"}},
+		{"change source", admissionv1.Update, sourced, resourced, false,
+			[]string{"spec", "immutable"}},
+		{"create with iam but not tls", admissionv1.Create, nil, iamOnly, false,
+			[]string{"spec.iamAuthorization", "requires encryptInTransit"}},
+		{"delete", admissionv1.Delete, mkSV(fs1, ap1), nil, true, nil},
+		{"no-op update", admissionv1.Update, mkSV(fs1, ap1), mkSV(fs1, ap1), true, nil},
+		{"metadata update", admissionv1.Update, mkSV(fs1, ap1), relabeled, true, nil},
+		{"status update", admissionv1.Update, mkSV(fs1, ap1), statused, true, nil},
+		{"change file system", admissionv1.Update, mkSV(fs1, ap1), mkSV(fs2, ap1), false,
+			[]string{"spec.fileSystemID", fs2, "immutable"}},
+		{"change access point", admissionv1.Update, mkSV(fs1, ap1), mkSV(fs1, ap2), false,
+			[]string{"spec.accessPointID", ap2, "immutable"}},
+		{"change both", admissionv1.Update, mkSV(fs1, ap1), mkSV(fs2, ap2), false,
+			[]string{"spec.fileSystemID", "spec.accessPointID"}},
+		{"change claim name", admissionv1.Update, claimed, renamed, false,
+			[]string{"spec", "immutable"}},
+		{"change access point spec", admissionv1.Update, provisioned, mkSV(fs1, ap1), false,
+			[]string{"spec.accessPointID", "immutable"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newValidator(t)
+			resp := v.Handle(context.TODO(), mkRequest(t, tt.op, tt.oldSV, tt.newSV))
+			if resp.Allowed != tt.allowed {
+				t.Fatalf("Expected Allowed=%v but got %v", tt.allowed, resp.Result)
+			}
+			// Denied() puts the explanation in the Reason, which the API server relays to the user.
+			for _, s := range tt.wantReason {
+				if !strings.Contains(string(resp.Result.Reason), s) {
+					t.Errorf("Expected reason to contain %q but got %q", s, resp.Result.Reason)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleOperator makes sure the operator is allowed to change the spec, so that it can revert
+// edits made while the webhook was unavailable.
+func TestHandleOperator(t *testing.T) {
+	v := newValidator(t)
+	v.operatorUsername = "system:serviceaccount:openshift-aws-efs:aws-efs-operator"
+	req := mkRequest(t, admissionv1.Update, mkSV("fs-1", "fsap-1"), mkSV("fs-2", "fsap-2"))
+
+	// Somebody else can't...
+	req.UserInfo.Username = "kube:admin"
+	if resp := v.Handle(context.TODO(), req); resp.Allowed {
+		t.Fatalf("Expected change by %s to be denied", req.UserInfo.Username)
+	}
+	// ...but the operator can.
+	req.UserInfo.Username = v.operatorUsername
+	if resp := v.Handle(context.TODO(), req); !resp.Allowed {
+		t.Fatalf("Expected change by the operator to be allowed but got %v", resp.Result)
+	}
+}
+
+// TestHandleGarbage covers the path where the request can't be decoded.
+func TestHandleGarbage(t *testing.T) {
+	v := newValidator(t)
+	req := mkRequest(t, admissionv1.Update, mkSV("fs-1", "fsap-1"), nil)
+	req.Object = runtime.RawExtension{Raw: []byte("not json")}
+	resp := v.Handle(context.TODO(), req)
+	if resp.Allowed || resp.Result.Code != http.StatusBadRequest {
+		t.Fatalf("Expected a BadRequest error but got %v", resp.Result)
+	}
+}
+
+// TestHandlePolicy covers the enforcement of SharedVolumePolicies at admission.
+func TestHandlePolicy(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := scheme.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	if err := awsefsv1alpha1.SchemeBuilder.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	v := newValidator(t)
+	v.reader = fake.NewFakeClientWithScheme(sch,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "proj1"}},
+		&awsefsv1alpha1.SharedVolumePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "proj1"},
+			Spec: awsefsv1alpha1.SharedVolumePolicySpec{
+				Namespaces: []string{"proj1"},
+				Allowed:    []awsefsv1alpha1.AllowedVolume{{FileSystemID: "fs-000001"}},
+			},
+		})
+
+	resp := v.Handle(context.TODO(), mkRequest(t, admissionv1.Create, nil, mkSV("fs-000001", "fsap-1111111d")))
+	if !resp.Allowed {
+		t.Fatalf("Expected an allowed file system to be allowed but got %v", resp.Result)
+	}
+	resp = v.Handle(context.TODO(), mkRequest(t, admissionv1.Create, nil, mkSV("fs-000002", "fsap-1111111d")))
+	if resp.Allowed || !strings.Contains(string(resp.Result.Reason), "SharedVolumePolicy proj1 does not allow") {
+		t.Fatalf("Expected a policy violation but got %v", resp.Result)
+	}
+	// SharedVolumes using a source are left to the controller.
+	sourced := mkSV("", "")
+	sourced.Spec.Source = "shared"
+	if resp := v.Handle(context.TODO(), mkRequest(t, admissionv1.Create, nil, sourced)); !resp.Allowed {
+		t.Fatalf("Expected a SharedVolume using a source to be allowed but got %v", resp.Result)
+	}
+}